@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"net/http"
+	"sync"
+)
+
+const (
+	// CodeUnknown is the business code reported for errors nothing else recognizes.
+	CodeUnknown HttpCode = 1
+	// CodeValidation is the business code reported for request validation failures.
+	CodeValidation HttpCode = 100
+	// CodeInvalidRequest is the business code reported when a request cannot be decoded.
+	CodeInvalidRequest HttpCode = 101
+	// CodeCanceled is the business code reported when the client canceled the request.
+	CodeCanceled HttpCode = 102
+	// CodeTimeout is the business code reported when handling the request timed out.
+	CodeTimeout HttpCode = 103
+	// CodeNotFound is the business code reported when a requested resource does not exist.
+	CodeNotFound HttpCode = 104
+	// CodeTooLarge is the business code reported when a request body or upload exceeds its limits.
+	CodeTooLarge HttpCode = 105
+	// CodeUnsupportedMediaType is the business code reported when uploaded content has a type
+	// that is not accepted.
+	CodeUnsupportedMediaType HttpCode = 106
+	// CodeDownload is the business code reported for download failures.
+	CodeDownload HttpCode = 200
+)
+
+// StatusClientClosedRequest is the non-standard status used when the client went away.
+const StatusClientClosedRequest = 499
+
+// Definition describes a named business error registered in the catalog.
+type Definition struct {
+	// Code is the business code placed in the response envelope.
+	Code HttpCode
+	// Message is the default message used when an error carries no message of its own.
+	// When empty, wrapped errors report their own message.
+	Message string
+	// Status is the HTTP status sent with the error.
+	Status int
+	// Key is a stable, language-independent identifier of the error, such as "user.not_found".
+	// It is not used to translate Message; response.SetProblemTypeBase turns it into the type
+	// URI of problem details.
+	Key string
+}
+
+var (
+	catalog = map[HttpCode]Definition{
+		CodeUnknown:              {Code: CodeUnknown, Message: "internal server error", Status: http.StatusInternalServerError, Key: "unknown"},
+		CodeValidation:           {Code: CodeValidation, Message: "validation failed", Status: http.StatusBadRequest, Key: "validation"},
+		CodeInvalidRequest:       {Code: CodeInvalidRequest, Status: http.StatusBadRequest, Key: "invalid_request"},
+		CodeCanceled:             {Code: CodeCanceled, Message: "request canceled", Status: StatusClientClosedRequest, Key: "canceled"},
+		CodeTimeout:              {Code: CodeTimeout, Message: "request timed out", Status: http.StatusGatewayTimeout, Key: "timeout"},
+		CodeNotFound:             {Code: CodeNotFound, Message: "resource not found", Status: http.StatusNotFound, Key: "not_found"},
+		CodeTooLarge:             {Code: CodeTooLarge, Message: "request entity too large", Status: http.StatusRequestEntityTooLarge, Key: "too_large"},
+		CodeUnsupportedMediaType: {Code: CodeUnsupportedMediaType, Message: "unsupported media type", Status: http.StatusUnsupportedMediaType, Key: "unsupported_media_type"},
+		CodeDownload:             {Code: CodeDownload, Message: "download failed", Status: http.StatusBadRequest, Key: "download"},
+	}
+	catalogMu sync.RWMutex
+)
+
+// Register adds definitions to the catalog, replacing any existing definition with the same code.
+func Register(defs ...Definition) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	for _, def := range defs {
+		catalog[def.Code] = def
+	}
+}
+
+// Lookup returns the definition registered for code.
+func Lookup(code HttpCode) (Definition, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	def, ok := catalog[code]
+	return def, ok
+}
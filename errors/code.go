@@ -0,0 +1,83 @@
+package errors
+
+import "net/http"
+
+// CodeError is an HttpError identified by a business code from the catalog.
+type CodeError struct {
+	code   HttpCode
+	msg    string
+	status int
+	key    string
+	Err    error
+}
+
+// New creates an HttpError for code. An empty msg falls back to the registered default message.
+func New(code HttpCode, msg string) HttpError {
+	e := newCodeError(code)
+	if msg != "" {
+		e.msg = msg
+	}
+
+	return e
+}
+
+// Wrap attaches code to err. The registered default message is reported when the code is in the
+// catalog, otherwise the message of err is used. Wrap returns nil when err is nil.
+func Wrap(err error, code HttpCode) HttpError {
+	if err == nil {
+		return nil
+	}
+
+	e := newCodeError(code)
+	e.Err = err
+	return e
+}
+
+func newCodeError(code HttpCode) *CodeError {
+	e := &CodeError{code: code, status: http.StatusBadRequest}
+	if def, ok := Lookup(code); ok {
+		e.msg = def.Message
+		e.key = def.Key
+		if def.Status != 0 {
+			e.status = def.Status
+		}
+	}
+
+	return e
+}
+
+// Code returns the business code of the error.
+func (e *CodeError) Code() HttpCode {
+	return e.code
+}
+
+// Status returns the HTTP status registered for the code, defaulting to 400.
+func (e *CodeError) Status() int {
+	return e.status
+}
+
+// Key returns the stable identifier registered for the code.
+func (e *CodeError) Key() string {
+	return e.key
+}
+
+// Error returns the error message, falling back to the wrapped error.
+func (e *CodeError) Error() string {
+	if e.msg == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+
+	return e.msg
+}
+
+// Unwrap exposes the wrapped error to errors.Is and errors.As.
+func (e *CodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a CodeError with the same business code, so errors created
+// with New can be used as sentinels.
+func (e *CodeError) Is(target error) bool {
+	t, ok := target.(*CodeError)
+	return ok && t.code == e.code
+}
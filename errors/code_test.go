@@ -0,0 +1,74 @@
+package errors
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestNewUsesRegisteredDefinition(t *testing.T) {
+	const code HttpCode = 40401
+	Register(Definition{Code: code, Message: "user not found", Status: http.StatusNotFound, Key: "user.not_found"})
+	t.Cleanup(func() { unregister(code) })
+
+	err := New(code, "")
+	codeErr, ok := err.(*CodeError)
+	if !ok {
+		t.Fatalf("expected *CodeError, got %T", err)
+	}
+	if codeErr.Code() != code || codeErr.Status() != http.StatusNotFound || codeErr.Key() != "user.not_found" {
+		t.Fatalf("unexpected error fields: %+v", codeErr)
+	}
+	if got := err.Error(); got != "user not found" {
+		t.Fatalf("expected default message, got %s", got)
+	}
+	if got := New(code, "user 42 not found").Error(); got != "user 42 not found" {
+		t.Fatalf("expected explicit message, got %s", got)
+	}
+}
+
+func TestNewUnregisteredCode(t *testing.T) {
+	err := New(99999, "boom").(*CodeError)
+
+	if err.Status() != http.StatusBadRequest {
+		t.Fatalf("expected default status 400, got %d", err.Status())
+	}
+	if _, ok := Lookup(99999); ok {
+		t.Fatalf("expected code to be unregistered")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(nil, CodeDownload) != nil {
+		t.Fatalf("expected nil when wrapping nil")
+	}
+
+	err := Wrap(io.ErrUnexpectedEOF, 99998)
+	if !stderrors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected wrapped error to be reachable")
+	}
+	if got := err.Error(); got != io.ErrUnexpectedEOF.Error() {
+		t.Fatalf("expected wrapped message for unregistered code, got %s", got)
+	}
+	if got := Wrap(io.ErrUnexpectedEOF, CodeDownload).Error(); got != "download failed" {
+		t.Fatalf("expected registered message, got %s", got)
+	}
+}
+
+func TestCodeErrorIsComparesCodes(t *testing.T) {
+	errNotFound := New(40402, "not found")
+
+	if !stderrors.Is(Wrap(io.EOF, 40402), errNotFound) {
+		t.Fatalf("expected errors with the same code to match")
+	}
+	if stderrors.Is(New(40403, "not found"), errNotFound) {
+		t.Fatalf("expected errors with different codes not to match")
+	}
+}
+
+func unregister(code HttpCode) {
+	catalogMu.Lock()
+	delete(catalog, code)
+	catalogMu.Unlock()
+}
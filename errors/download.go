@@ -1,6 +1,12 @@
 package errors
 
-// DownloadError wraps an underlying download failure with an HTTP error code.
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+)
+
+// DownloadError wraps an underlying download failure with a business code.
 type DownloadError struct {
 	code HttpCode
 	Err  error
@@ -8,15 +14,33 @@ type DownloadError struct {
 
 // NewDownloadError creates an HttpError that reports download failures.
 func NewDownloadError(err error) HttpError {
-	return &DownloadError{200, err}
+	return &DownloadError{CodeDownload, err}
 }
 
-// Code returns the HTTP status code that should be sent to the caller.
+// Code returns the business code that should be sent to the caller.
 func (e DownloadError) Code() HttpCode {
 	return e.code
 }
 
+// Status maps the underlying failure to an HTTP status: 404 for missing files, 403 for
+// permission and download policy failures, and 400 otherwise.
+func (e DownloadError) Status() int {
+	switch {
+	case errors.Is(e.Err, fs.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(e.Err, fs.ErrPermission):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 // Error exposes the underlying download error message.
 func (e DownloadError) Error() string {
 	return e.Err.Error()
 }
+
+// Unwrap exposes the underlying download error to errors.Is and errors.As.
+func (e DownloadError) Unwrap() error {
+	return e.Err
+}
@@ -1,10 +1,24 @@
 package errors
 
-// HttpCode represents the HTTP status code associated with an HttpError.
+// HttpCode represents the business code associated with an HttpError.
 type HttpCode int
 
-// HttpError augments the standard error interface with an HTTP response code.
+// HttpError augments the standard error interface with a business code.
 type HttpError interface {
 	error
 	Code() HttpCode
 }
+
+// StatusError is an HttpError that also decides the HTTP status sent to the client,
+// keeping it independent from the business code placed in the response body.
+type StatusError interface {
+	HttpError
+	Status() int
+}
+
+// DataError is an HttpError that carries details for the data field of error responses,
+// such as the field violations of a failed validation.
+type DataError interface {
+	HttpError
+	Data() any
+}
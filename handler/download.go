@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/response"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// File is the result of a download handler. Content is served when set; otherwise Path is
+// served through the Downloader, within its root.
+type File struct {
+	// Path is the file to serve, relative to the Downloader root.
+	Path string
+	// Content is an in-memory or seekable payload, such as a generated export.
+	Content io.ReadSeeker
+	// Name is the file name presented to the client. It defaults to the base name of Path and
+	// is required with Content.
+	Name string
+	// ModTime is the modification time reported for Content.
+	ModTime time.Time
+	// Options adjust the Content-Disposition, e.g. response.Inline().
+	Options []response.ServeOption
+}
+
+// HandleDownload adapts fn into a handler serving the returned File with Range and conditional
+// request support. Errors, including failures to open the file, are written with
+// response.ErrorCtx; a nil File is answered with errors.CodeNotFound.
+func HandleDownload[Req any](fn func(ctx context.Context, req *Req) (*File, error), opts ...Option) http.HandlerFunc {
+	o := buildOptions(opts)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decode[Req](w, r, o)
+		if !ok {
+			return
+		}
+
+		file, err := fn(r.Context(), req)
+		if err == nil {
+			err = checkFile(file)
+		}
+		if err != nil {
+			response.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		if file.Content != nil {
+			o.downloader.ServeContent(w, r, file.Name, file.ModTime, file.Content, file.Options...)
+			return
+		}
+
+		serveOpts := file.Options
+		if file.Name != "" {
+			serveOpts = append([]response.ServeOption{response.Filename(file.Name)}, serveOpts...)
+		}
+		o.downloader.ServeDownload(w, r, file.Path, nil, serveOpts...)
+	}
+}
+
+// checkFile rejects results that cannot be served: a nil File is reported as
+// errors.CodeNotFound, and Content without a Name as a server error.
+func checkFile(file *File) error {
+	if file == nil {
+		return errors.New(errors.CodeNotFound, "")
+	}
+	if file.Content != nil && file.Name == "" {
+		return fmt.Errorf("download: File.Name is required with File.Content")
+	}
+
+	return nil
+}
+
+// HandleStream adapts fn, which writes its own response body such as server-sent events or a
+// CSV export, into a handler. An error returned before fn wrote anything is written with
+// response.ErrorCtx; once the response has started it can only be logged.
+func HandleStream[Req any](fn func(ctx context.Context, req *Req, w http.ResponseWriter) error,
+	opts ...Option) http.HandlerFunc {
+	o := buildOptions(opts)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decode[Req](w, r, o)
+		if !ok {
+			return
+		}
+
+		sw := &startedWriter{ResponseWriter: w}
+		if err := fn(r.Context(), req, sw); err != nil {
+			if sw.started {
+				logx.WithContext(r.Context()).Errorf("stream response: %v", err)
+				return
+			}
+
+			response.ErrorCtx(r.Context(), w, err)
+		}
+	}
+}
+
+// startedWriter records whether the response has started.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *startedWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends buffered data to the client, starting the response.
+func (w *startedWriter) Flush() {
+	w.started = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *startedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,121 @@
+// Package handler adapts typed business functions to http.HandlerFunc, decoding the request with
+// the request package and writing results and errors with the response package.
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/starme/go-zero/httpx/request"
+	"github.com/starme/go-zero/httpx/response"
+)
+
+// Option configures an adapter.
+type Option func(*options)
+
+type options struct {
+	parse      func(r *http.Request, v any, opts ...request.Option) error
+	parseOpts  []request.Option
+	downloader *response.Downloader
+}
+
+// WithParser decodes requests with parse, such as request.ParseJsonBody or request.ParseForm,
+// instead of request.Parse.
+func WithParser(parse func(r *http.Request, v any, opts ...request.Option) error) Option {
+	return func(o *options) {
+		o.parse = parse
+	}
+}
+
+// WithRequestOptions passes opts, such as request.WithStrictJSON or request.WithValidator,
+// to the parser.
+func WithRequestOptions(opts ...request.Option) Option {
+	return func(o *options) {
+		o.parseOpts = append(o.parseOpts, opts...)
+	}
+}
+
+// WithDownloader serves the files of HandleDownload with d instead of response.DefaultDownloader.
+func WithDownloader(d *response.Downloader) Option {
+	return func(o *options) {
+		o.downloader = d
+	}
+}
+
+func buildOptions(opts []Option) options {
+	o := options{parse: request.Parse}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.downloader == nil {
+		o.downloader = response.DefaultDownloader()
+	}
+
+	return o
+}
+
+// decode parses and validates the request into a new Req, writing the error response when
+// that fails.
+func decode[Req any](w http.ResponseWriter, r *http.Request, o options) (*Req, bool) {
+	req := new(Req)
+	if err := o.parse(r, req, o.parseOpts...); err != nil {
+		response.ErrorCtx(r.Context(), w, err)
+		return nil, false
+	}
+
+	return req, true
+}
+
+// Handle adapts fn into a handler: the request is decoded and validated into Req with
+// request.Parse, fn's result is written with response.SuccessCtx, and errors from either step
+// are written with response.ErrorCtx, which maps them to errors.HttpError.
+func Handle[Req, Resp any](fn func(ctx context.Context, req *Req) (*Resp, error), opts ...Option) http.HandlerFunc {
+	o := buildOptions(opts)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decode[Req](w, r, o)
+		if !ok {
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			response.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		response.SuccessCtx(r.Context(), w, resp)
+	}
+}
+
+// HandleNoRequest adapts fn, which takes no input, like Handle.
+func HandleNoRequest[Resp any](fn func(ctx context.Context) (*Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := fn(r.Context())
+		if err != nil {
+			response.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		response.SuccessCtx(r.Context(), w, resp)
+	}
+}
+
+// HandleNoResponse adapts fn, which returns no data, like Handle. Success is written without data.
+func HandleNoResponse[Req any](fn func(ctx context.Context, req *Req) error, opts ...Option) http.HandlerFunc {
+	o := buildOptions(opts)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decode[Req](w, r, o)
+		if !ok {
+			return
+		}
+
+		if err := fn(r.Context(), req); err != nil {
+			response.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		response.SuccessCtx(r.Context(), w)
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/request"
+	"github.com/starme/go-zero/httpx/response"
+	"github.com/stretchr/testify/require"
+)
+
+type greetRequest struct {
+	Name string `json:"name,optional" validate:"required"`
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func greet(_ context.Context, req *greetRequest) (*greetResponse, error) {
+	if req.Name == "nobody" {
+		return nil, errors.New(errors.CodeNotFound, "")
+	}
+
+	return &greetResponse{Greeting: "hello " + req.Name}, nil
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{"success", `{"name":"alice"}`, http.StatusOK, `{"code":0,"msg":"success","data":{"greeting":"hello alice"}}`},
+		{"validation", `{}`, http.StatusBadRequest, `"code":100`},
+		{"decode", `{"name":`, http.StatusBadRequest, `"code":101`},
+		{"logic", `{"name":"nobody"}`, http.StatusNotFound, `{"code":104,"msg":"resource not found","data":null}`},
+	}
+
+	h := Handle(greet)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h(rec, newJsonRequest(tt.body))
+
+			require.Equal(t, tt.status, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestHandleWithRequestOptions(t *testing.T) {
+	h := Handle(greet, WithParser(request.ParseJsonBody), WithRequestOptions(request.WithStrictJSON()))
+
+	rec := httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"alice","nmae":"bob"}`))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), `"rule":"unknown_field"`)
+}
+
+func TestHandleNoRequestAndNoResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleNoRequest(func(context.Context) (*greetResponse, error) {
+		return &greetResponse{Greeting: "hi"}, nil
+	})(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, rec.Body.String(), `"greeting":"hi"`)
+
+	var got string
+	rec = httptest.NewRecorder()
+	HandleNoResponse(func(_ context.Context, req *greetRequest) error {
+		got = req.Name
+		return nil
+	})(rec, newJsonRequest(`{"name":"alice"}`))
+	require.Equal(t, "alice", got)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"msg":"success"`)
+}
+
+func TestHandleDownload(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "report.export"), []byte("file data"), 0o644))
+	d, err := response.NewDownloader(root)
+	require.NoError(t, err)
+
+	h := HandleDownload(func(_ context.Context, req *greetRequest) (*File, error) {
+		switch req.Name {
+		case "content":
+			return &File{Content: bytes.NewReader([]byte("generated")), Name: "export.csv", ModTime: time.Now()}, nil
+		case "missing":
+			return &File{Path: "missing.export"}, nil
+		case "nil":
+			return nil, nil
+		case "unnamed":
+			return &File{Content: bytes.NewReader([]byte("generated"))}, nil
+		default:
+			return &File{Path: "report.export", Name: "Report.export", Options: []response.ServeOption{response.Inline()}}, nil
+		}
+	}, WithDownloader(d))
+
+	rec := httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"file"}`))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "file data", rec.Body.String())
+	require.True(t, strings.HasPrefix(rec.Header().Get("Content-Disposition"), `inline; filename="Report.export"`))
+
+	rec = httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"content"}`))
+	require.Equal(t, "generated", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"missing"}`))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"nil"}`))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"unnamed"}`))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Empty(t, rec.Header().Get("Content-Disposition"))
+}
+
+func TestHandleStream(t *testing.T) {
+	errBoom := stderrors.New("boom")
+	h := HandleStream(func(_ context.Context, req *greetRequest, w http.ResponseWriter) error {
+		if req.Name == "early" {
+			return errors.New(errors.CodeNotFound, "")
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("a,b\n"))
+		return errBoom
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"early"}`))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h(rec, newJsonRequest(`{"name":"late"}`))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "a,b\n", rec.Body.String())
+}
+
+func newJsonRequest(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
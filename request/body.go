@@ -0,0 +1,248 @@
+package request
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/andybalholm/brotli"
+	"github.com/starme/go-zero/httpx/errors"
+)
+
+// DefaultMaxDecompressedSize caps the decoded size of compressed request bodies unless
+// SetMaxDecompressedSize or WithMaxDecompressedSize choose another limit.
+const DefaultMaxDecompressedSize = 32 << 20
+
+var (
+	maxBodySize         atomic.Int64
+	maxDecompressedSize atomic.Int64
+)
+
+func init() {
+	maxDecompressedSize.Store(DefaultMaxDecompressedSize)
+}
+
+// SetMaxBodySize limits the bytes read from the body of every request decoded by this package.
+// Zero, the default, leaves bodies unlimited. Larger bodies are rejected with
+// errors.CodeTooLarge (413).
+func SetMaxBodySize(n int64) {
+	maxBodySize.Store(n)
+}
+
+// SetMaxDecompressedSize limits the decoded size of request bodies sent with a Content-Encoding,
+// DefaultMaxDecompressedSize by default. Zero removes the limit.
+func SetMaxDecompressedSize(n int64) {
+	maxDecompressedSize.Store(n)
+}
+
+// WithMaxBodySize overrides SetMaxBodySize for a single call.
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = &n
+	}
+}
+
+// WithMaxDecompressedSize overrides SetMaxDecompressedSize for a single call.
+func WithMaxDecompressedSize(n int64) Option {
+	return func(o *options) {
+		o.maxDecompressedSize = &n
+	}
+}
+
+// bodyLimit returns the limit of the raw body; the multipart size limit takes precedence.
+func (o options) bodyLimit() int64 {
+	switch {
+	case o.maxTotalSize > 0:
+		return o.maxTotalSize
+	case o.maxBodySize != nil:
+		return *o.maxBodySize
+	default:
+		return maxBodySize.Load()
+	}
+}
+
+func (o options) decompressedLimit() int64 {
+	if o.maxDecompressedSize != nil {
+		return *o.maxDecompressedSize
+	}
+
+	return maxDecompressedSize.Load()
+}
+
+// bodyGuard remembers the limits applied to a request body, so that exceeding them is reported
+// as errors.CodeTooLarge however the decoder wrapped the read error.
+type bodyGuard struct {
+	readers []*limitedReader
+}
+
+// prepareBody limits the body of r and transparently decodes its Content-Encoding. The header
+// is removed once the body is decoded.
+func (o options) prepareBody(r *http.Request) (*bodyGuard, error) {
+	guard := &bodyGuard{}
+	if r.Body == nil || r.Body == http.NoBody {
+		return guard, nil
+	}
+
+	limit := o.bodyLimit()
+	if limit > 0 && r.ContentLength > limit {
+		return nil, tooLargeErr("request body", limit)
+	}
+
+	body := io.Reader(r.Body)
+	if limit > 0 {
+		body = guard.limit(body, "request body", limit)
+	}
+
+	encodings := contentEncodings(r.Header)
+	if len(encodings) > 0 {
+		decoded, err := decodeBody(body, encodings)
+		if err != nil {
+			return nil, err
+		}
+		if limit := o.decompressedLimit(); limit > 0 {
+			decoded = guard.limit(decoded, "decompressed request body", limit)
+		}
+
+		body = decoded
+		r.Header.Del("Content-Encoding")
+	}
+
+	r.Body = readCloser{Reader: body, Closer: r.Body}
+	return guard, nil
+}
+
+// wrap reports a decoding failure caused by a body limit as errors.CodeTooLarge and any other
+// failure like wrapParseErr.
+func (g *bodyGuard) wrap(err error) error {
+	if tooLarge := g.exceeded(); tooLarge != nil {
+		return tooLarge
+	}
+
+	return wrapParseErr(err)
+}
+
+// exceeded returns the error of the first limit the body ran into, if any.
+func (g *bodyGuard) exceeded() error {
+	for _, lr := range g.readers {
+		if lr.exceeded {
+			return lr.err
+		}
+	}
+
+	return nil
+}
+
+func (g *bodyGuard) limit(r io.Reader, what string, n int64) io.Reader {
+	lr := &limitedReader{r: r, n: n, what: what, limit: n}
+	g.readers = append(g.readers, lr)
+	return lr
+}
+
+// contentEncodings lists the codings of the Content-Encoding header in the order they were
+// applied, ignoring identity.
+func contentEncodings(header http.Header) []string {
+	var encodings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				encodings = append(encodings, coding)
+			}
+		}
+	}
+
+	return encodings
+}
+
+// decodeBody undoes encodings in reverse order of application.
+func decodeBody(body io.Reader, encodings []string) (io.Reader, error) {
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = newDeflateReader(body)
+		case "br":
+			body = brotli.NewReader(body)
+		default:
+			return nil, errors.New(errors.CodeUnsupportedMediaType,
+				fmt.Sprintf("unsupported content encoding %q", encodings[i]))
+		}
+		if err != nil {
+			return nil, errors.Wrap(fmt.Errorf("decode %s request body: %w", encodings[i], err),
+				errors.CodeInvalidRequest)
+		}
+	}
+
+	return body, nil
+}
+
+// newDeflateReader decodes "deflate" bodies, which should be zlib streams but are sent as raw
+// DEFLATE data by some clients.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil && !stderrors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+
+	return flate.NewReader(br), nil
+}
+
+// limitedReader fails with errors.CodeTooLarge once more than limit bytes are read, in the
+// manner of http.MaxBytesReader.
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	limit    int64
+	what     string
+	exceeded bool
+	err      error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Read one byte past the limit to tell a body of exactly limit bytes from a larger one.
+	if int64(len(p))-1 > l.n {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) <= l.n {
+		l.n -= int64(n)
+		l.err = err
+		return n, err
+	}
+
+	n = int(l.n)
+	l.n = 0
+	l.exceeded = true
+	l.err = tooLargeErr(l.what, l.limit)
+	return n, l.err
+}
+
+func tooLargeErr(what string, limit int64) errors.HttpError {
+	return errors.New(errors.CodeTooLarge, fmt.Sprintf("%s exceeds %d bytes", what, limit))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,142 @@
+package request
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const loginBody = `{"account":"alice","password":"secret"}`
+
+func TestParseJsonBodyDecompresses(t *testing.T) {
+	tests := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	}
+
+	for encoding, newWriter := range tests {
+		t.Run(encoding, func(t *testing.T) {
+			r := newEncodedRequest(t, encoding, compress(t, newWriter, loginBody))
+
+			var req loginRequest
+			require.NoError(t, ParseJsonBody(r, &req))
+			require.Equal(t, loginRequest{Account: "alice", Password: "secret"}, req)
+			require.Empty(t, r.Header.Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestParseJsonBodyAcceptsRawDeflate(t *testing.T) {
+	body := compress(t, func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	}, loginBody)
+
+	var req loginRequest
+	require.NoError(t, ParseJsonBody(newEncodedRequest(t, "deflate", body), &req))
+	require.Equal(t, "alice", req.Account)
+}
+
+func TestParseJsonBodyLimits(t *testing.T) {
+	bomb := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		`{"account":"`+strings.Repeat("a", 1<<20)+`"}`)
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		opts []Option
+		code errors.HttpCode
+	}{
+		{
+			name: "declared length",
+			r:    newJsonRequest(loginBody),
+			opts: []Option{WithMaxBodySize(8)},
+			code: errors.CodeTooLarge,
+		},
+		{
+			name: "undeclared length",
+			r: func() *http.Request {
+				r := newJsonRequest(loginBody)
+				r.ContentLength = 1
+				return r
+			}(),
+			opts: []Option{WithMaxBodySize(8)},
+			code: errors.CodeTooLarge,
+		},
+		{
+			name: "decompressed size",
+			r:    newEncodedRequest(t, "gzip", bomb),
+			opts: []Option{WithMaxDecompressedSize(1 << 10)},
+			code: errors.CodeTooLarge,
+		},
+		{
+			name: "unknown encoding",
+			r:    newEncodedRequest(t, "compress", []byte(loginBody)),
+			code: errors.CodeUnsupportedMediaType,
+		},
+		{
+			name: "corrupt encoding",
+			r:    newEncodedRequest(t, "gzip", []byte(loginBody)),
+			code: errors.CodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req loginRequest
+			err := ParseJsonBody(tt.r, &req, tt.opts...)
+
+			var httpErr errors.HttpError
+			require.True(t, stderrors.As(err, &httpErr), "got %v", err)
+			require.Equal(t, tt.code, httpErr.Code())
+		})
+	}
+}
+
+func TestSetMaxBodySize(t *testing.T) {
+	SetMaxBodySize(8)
+	t.Cleanup(func() { SetMaxBodySize(0) })
+
+	var req loginRequest
+	err := Parse(newJsonRequest(loginBody), &req)
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr))
+	require.Equal(t, errors.CodeTooLarge, httpErr.Code())
+
+	// A per-call limit overrides the global one.
+	require.NoError(t, Parse(newJsonRequest(loginBody), &req, WithMaxBodySize(1<<10)))
+}
+
+func compress(t *testing.T, newWriter func(io.Writer) io.WriteCloser, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	_, err := io.WriteString(w, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func newEncodedRequest(t *testing.T, encoding string, body []byte) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", encoding)
+	return r
+}
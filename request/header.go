@@ -0,0 +1,51 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/mapping"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+const cookieKey = "cookie"
+
+var cookieUnmarshaler = mapping.NewUnmarshaler(
+	cookieKey,
+	mapping.WithStringValues(),
+	mapping.WithOpaqueKeys(),
+	mapping.WithFromArray())
+
+// ParseHeaders binds request headers into the fields of v tagged with `header:"X-Tenant-Id"`
+// and validates the result. Header names are matched case-insensitively, values are converted
+// to the field types, and tag options such as `header:"X-Page-Size,default=20"` and `optional`
+// apply.
+func ParseHeaders(r *http.Request, v any, opts ...Option) error {
+	if err := httpx.ParseHeaders(r, v); err != nil {
+		return wrapParseErr(err)
+	}
+
+	return buildOptions(opts).validate(r.Context(), v)
+}
+
+// ParseCookies binds request cookies into the fields of v tagged with `cookie:"session"` and
+// validates the result. Cookie names are case-sensitive; a cookie sent several times binds to
+// a slice field, and tag options such as `default=` and `optional` apply.
+func ParseCookies(r *http.Request, v any, opts ...Option) error {
+	cookies := map[string]any{}
+	for _, cookie := range r.Cookies() {
+		switch existing := cookies[cookie.Name].(type) {
+		case nil:
+			cookies[cookie.Name] = cookie.Value
+		case string:
+			cookies[cookie.Name] = []string{existing, cookie.Value}
+		case []string:
+			cookies[cookie.Name] = append(existing, cookie.Value)
+		}
+	}
+
+	if err := cookieUnmarshaler.Unmarshal(cookies, v); err != nil {
+		return wrapParseErr(err)
+	}
+
+	return buildOptions(opts).validate(r.Context(), v)
+}
@@ -0,0 +1,174 @@
+package request
+
+import (
+	stderrors "errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+const (
+	fileKey = "file"
+
+	// defaultMaxMemory is the part of a multipart body kept in memory; the rest of the
+	// uploaded files is stored in temporary files.
+	defaultMaxMemory = 32 << 20
+)
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// WithMaxFileSize rejects uploads containing a file larger than n bytes.
+func WithMaxFileSize(n int64) Option {
+	return func(o *options) {
+		o.maxFileSize = n
+	}
+}
+
+// WithMaxTotalSize rejects multipart bodies larger than n bytes without reading past the limit.
+// It takes precedence over WithMaxBodySize.
+func WithMaxTotalSize(n int64) Option {
+	return func(o *options) {
+		o.maxTotalSize = n
+	}
+}
+
+// WithMaxFiles rejects uploads containing more than n files.
+func WithMaxFiles(n int) Option {
+	return func(o *options) {
+		o.maxFiles = n
+	}
+}
+
+// WithAllowedMimeTypes rejects uploaded files whose content, sniffed from their first bytes,
+// matches none of types. Entries may be wildcards such as "image/*".
+func WithAllowedMimeTypes(types ...string) Option {
+	return func(o *options) {
+		o.allowedMimeTypes = types
+	}
+}
+
+// ParseMultipart decodes a multipart/form-data request into v and validates it. Form values
+// bind to fields tagged `form:"name"` as in ParseForm; uploaded files bind to fields tagged
+// `file:"name"` of type *multipart.FileHeader or []*multipart.FileHeader.
+//
+// Uploads exceeding WithMaxTotalSize, WithMaxFileSize or WithMaxFiles are rejected with
+// errors.CodeTooLarge (413), and files outside WithAllowedMimeTypes with
+// errors.CodeUnsupportedMediaType (415), before v is validated.
+func ParseMultipart(r *http.Request, v any, opts ...Option) error {
+	o := buildOptions(opts)
+	guard, err := o.prepareBody(r)
+	if err != nil {
+		return err
+	}
+
+	if err = r.ParseMultipartForm(defaultMaxMemory); err != nil {
+		return guard.wrap(wrapMultipartErr(err))
+	}
+	if err = o.checkFiles(r.MultipartForm.File); err != nil {
+		return err
+	}
+
+	if err = httpx.ParseForm(r, v); err != nil {
+		return wrapParseErr(err)
+	}
+	if err = bindFiles(r.MultipartForm.File, v); err != nil {
+		return err
+	}
+
+	return o.validate(r.Context(), v)
+}
+
+// checkFiles enforces the upload limits on every file of the form.
+func (o options) checkFiles(files map[string][]*multipart.FileHeader) error {
+	var count int
+	for field, headers := range files {
+		count += len(headers)
+		if o.maxFiles > 0 && count > o.maxFiles {
+			return errors.New(errors.CodeTooLarge, fmt.Sprintf("too many files, at most %d allowed", o.maxFiles))
+		}
+
+		for _, fh := range headers {
+			if o.maxFileSize > 0 && fh.Size > o.maxFileSize {
+				return errors.New(errors.CodeTooLarge,
+					fmt.Sprintf("file %q of field %s exceeds %d bytes", fh.Filename, field, o.maxFileSize))
+			}
+
+			if len(o.allowedMimeTypes) == 0 {
+				continue
+			}
+			ok, err := validation.MatchFileType(fh, o.allowedMimeTypes...)
+			if err != nil {
+				return fmt.Errorf("sniff file %q: %w", fh.Filename, err)
+			}
+			if !ok {
+				return errors.New(errors.CodeUnsupportedMediaType,
+					fmt.Sprintf("file %q of field %s has an unsupported type", fh.Filename, field))
+			}
+		}
+	}
+
+	return nil
+}
+
+// bindFiles stores the uploaded files in the fields of v tagged with `file:"name"`.
+func bindFiles(files map[string][]*multipart.FileHeader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind files: %T is not a pointer to a struct", v)
+	}
+
+	return bindFileFields(files, rv.Elem())
+}
+
+func bindFileFields(files map[string][]*multipart.FileHeader, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindFileFields(files, rv.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(fileKey)
+		if !ok || !field.IsExported() {
+			continue
+		}
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		headers := files[name]
+		switch field.Type {
+		case fileHeaderType:
+			if len(headers) > 0 {
+				rv.Field(i).Set(reflect.ValueOf(headers[0]))
+			}
+		case reflect.SliceOf(fileHeaderType):
+			rv.Field(i).Set(reflect.ValueOf(headers))
+		default:
+			return fmt.Errorf("bind files: field %s of type %s cannot hold uploaded files", field.Name, field.Type)
+		}
+	}
+
+	return nil
+}
+
+// wrapMultipartErr reports oversized bodies as errors.CodeTooLarge and other failures like
+// decoding errors.
+func wrapMultipartErr(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) || stderrors.Is(err, multipart.ErrMessageTooLarge) {
+		return errors.Wrap(err, errors.CodeTooLarge)
+	}
+
+	return wrapParseErr(err)
+}
@@ -0,0 +1,112 @@
+package request
+
+import (
+	"bytes"
+	stderrors "errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type uploadRequest struct {
+	Title       string                  `form:"title"`
+	Avatar      *multipart.FileHeader   `file:"avatar" validate:"required,maxsize=1KB,mimetype=image/png,ext=png"`
+	Attachments []*multipart.FileHeader `file:"attachments" validate:"max=2"`
+}
+
+type uploadFile struct {
+	field, name string
+	content     []byte
+}
+
+func TestParseMultipartBindsFieldsAndFiles(t *testing.T) {
+	r := newMultipartRequest(t, map[string]string{"title": "hello"},
+		uploadFile{"avatar", "me.png", pngHeader},
+		uploadFile{"attachments", "a.txt", []byte("a")},
+		uploadFile{"attachments", "b.txt", []byte("b")})
+
+	var req uploadRequest
+	require.NoError(t, ParseMultipart(r, &req))
+	require.Equal(t, "hello", req.Title)
+	require.Equal(t, "me.png", req.Avatar.Filename)
+	require.Len(t, req.Attachments, 2)
+	require.Equal(t, "b.txt", req.Attachments[1].Filename)
+}
+
+func TestParseMultipartValidatesFileTags(t *testing.T) {
+	r := newMultipartRequest(t, map[string]string{"title": "hello"},
+		uploadFile{"avatar", "me.gif", []byte("GIF89a")})
+	r = r.WithContext(validation.WithLocale(r.Context(), "en"))
+
+	var req uploadRequest
+	err := ParseMultipart(r, &req)
+
+	var ve validation.ValidateError
+	require.True(t, stderrors.As(err, &ve))
+	require.Equal(t, "avatar must be a file of type image/png", err.Error())
+}
+
+func TestParseMultipartEnforcesLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		code errors.HttpCode
+	}{
+		{"total size", WithMaxTotalSize(64), errors.CodeTooLarge},
+		{"file size", WithMaxFileSize(8), errors.CodeTooLarge},
+		{"file count", WithMaxFiles(1), errors.CodeTooLarge},
+		{"mime type", WithAllowedMimeTypes("image/*"), errors.CodeUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMultipartRequest(t, map[string]string{"title": "hello"},
+				uploadFile{"avatar", "me.png", pngHeader},
+				uploadFile{"attachments", "a.txt", []byte("plain text")})
+
+			var req uploadRequest
+			err := ParseMultipart(r, &req, tt.opt)
+
+			var httpErr errors.HttpError
+			require.True(t, stderrors.As(err, &httpErr), "got %v", err)
+			require.Equal(t, tt.code, httpErr.Code())
+		})
+	}
+}
+
+func TestParseMultipartRejectsOtherContent(t *testing.T) {
+	var req uploadRequest
+	err := ParseMultipart(newJsonRequest(`{}`), &req)
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr))
+	require.Equal(t, errors.CodeInvalidRequest, httpErr.Code())
+}
+
+func newMultipartRequest(t *testing.T, values map[string]string, files ...uploadFile) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range values {
+		require.NoError(t, mw.WriteField(name, value))
+	}
+	for _, f := range files {
+		w, err := mw.CreateFormFile(f.field, f.name)
+		require.NoError(t, err)
+		_, err = w.Write(f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
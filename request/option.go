@@ -0,0 +1,46 @@
+package request
+
+import (
+	"context"
+
+	"github.com/starme/go-zero/httpx/validation"
+)
+
+// Option customizes a single Parse call.
+type Option func(*options)
+
+type options struct {
+	validator           *validation.Validator
+	maxBodySize         *int64
+	maxDecompressedSize *int64
+	maxFileSize         int64
+	maxTotalSize        int64
+	maxFiles            int
+	allowedMimeTypes    []string
+	strictJSON          bool
+}
+
+// WithValidator validates the parsed value with v instead of the default validator.
+func WithValidator(v *validation.Validator) Option {
+	return func(o *options) {
+		o.validator = v
+	}
+}
+
+func buildOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// validate runs the configured validator, or the package default, against v.
+func (o options) validate(ctx context.Context, v any) error {
+	if o.validator != nil {
+		return o.validator.ValidateStruct(ctx, v)
+	}
+
+	return validation.Validate(ctx, v)
+}
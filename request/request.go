@@ -1,45 +1,84 @@
 package request
 
 import (
+	stderrors "errors"
 	"net/http"
 
-	"github.com/starme/go-zero/httpx/validation"
+	"github.com/starme/go-zero/httpx/errors"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
 // Parse decodes the incoming request into v and validates the resulting struct.
-func Parse(r *http.Request, v any) error {
-	if err := httpx.Parse(r, v); err != nil {
+//
+// Parse, ParseForm, ParseJsonBody and the multipart helpers limit the request body to
+// SetMaxBodySize or WithMaxBodySize and decode gzip, deflate and br Content-Encodings,
+// capping the decoded size at SetMaxDecompressedSize or WithMaxDecompressedSize. Exceeding a
+// limit is reported as errors.CodeTooLarge (413), an unknown encoding as
+// errors.CodeUnsupportedMediaType (415).
+func Parse(r *http.Request, v any, opts ...Option) error {
+	o := buildOptions(opts)
+	guard, err := o.prepareBody(r)
+	if err != nil {
 		return err
 	}
+	if err = o.checkStrictJSON(r, guard, v); err != nil {
+		return err
+	}
+	if err = httpx.Parse(r, v); err != nil {
+		return guard.wrap(err)
+	}
 
-	return validation.Validate(r.Context(), v)
+	return o.validate(r.Context(), v)
 }
 
 // ParseForm reads form values from the request body or query string into v and validates it.
-func ParseForm(r *http.Request, v any) error {
-	if err := httpx.ParseForm(r, v); err != nil {
+func ParseForm(r *http.Request, v any, opts ...Option) error {
+	o := buildOptions(opts)
+	guard, err := o.prepareBody(r)
+	if err != nil {
 		return err
 	}
+	if err = httpx.ParseForm(r, v); err != nil {
+		return guard.wrap(err)
+	}
 
-	return validation.Validate(r.Context(), v)
+	return o.validate(r.Context(), v)
 }
 
 // ParseJsonBody decodes a JSON payload from the request body into v and validates it.
-func ParseJsonBody(r *http.Request, v any) error {
-	if err := httpx.ParseJsonBody(r, v); err != nil {
+func ParseJsonBody(r *http.Request, v any, opts ...Option) error {
+	o := buildOptions(opts)
+	guard, err := o.prepareBody(r)
+	if err != nil {
+		return err
+	}
+	if err = o.checkStrictJSON(r, guard, v); err != nil {
 		return err
 	}
+	if err = httpx.ParseJsonBody(r, v); err != nil {
+		return guard.wrap(err)
+	}
 
-	return validation.Validate(r.Context(), v)
+	return o.validate(r.Context(), v)
 }
 
 // ParsePath binds URI path parameters into v and validates the result.
 // For example: http://localhost/bag/:name.
-func ParsePath(r *http.Request, v any) error {
+func ParsePath(r *http.Request, v any, opts ...Option) error {
 	if err := httpx.ParsePath(r, v); err != nil {
+		return wrapParseErr(err)
+	}
+
+	return buildOptions(opts).validate(r.Context(), v)
+}
+
+// wrapParseErr reports go-zero decoding failures as errors.CodeInvalidRequest, keeping errors
+// that already carry a business code.
+func wrapParseErr(err error) error {
+	var httpErr errors.HttpError
+	if stderrors.As(err, &httpErr) {
 		return err
 	}
 
-	return validation.Validate(r.Context(), v)
+	return errors.Wrap(err, errors.CodeInvalidRequest)
 }
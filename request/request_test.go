@@ -0,0 +1,127 @@
+package request
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+	"github.com/stretchr/testify/require"
+)
+
+type loginRequest struct {
+	Account  string `json:"account,optional" validate:"required"`
+	Password string `json:"password,optional" validate:"required"`
+}
+
+func TestParseJsonBodyValidates(t *testing.T) {
+	var req loginRequest
+	err := ParseJsonBody(newJsonRequest(`{"account":"alice"}`), &req)
+
+	var ve validation.ValidateError
+	require.True(t, stderrors.As(err, &ve))
+	require.Equal(t, "alice", req.Account)
+	require.Len(t, ve, 1)
+}
+
+func TestParseJsonBodyWrapsDecodeErrors(t *testing.T) {
+	var req loginRequest
+	err := ParseJsonBody(newJsonRequest(`{"account":`), &req)
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr))
+	require.Equal(t, errors.CodeInvalidRequest, httpErr.Code())
+}
+
+func TestParseWithValidator(t *testing.T) {
+	v, err := validation.New(validation.WithTagNameFunc(func(fld reflect.StructField) string {
+		return strings.ToUpper(fld.Name)
+	}))
+	require.NoError(t, err)
+
+	var req loginRequest
+	r := newJsonRequest(`{"account":"alice"}`)
+	r = r.WithContext(validation.WithLocale(context.Background(), "en"))
+	err = Parse(r, &req, WithValidator(v))
+
+	require.EqualError(t, err, "PASSWORD is a required field")
+}
+
+func newJsonRequest(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+type headerOnly struct {
+	TenantID int64  `header:"X-Tenant-Id" validate:"gt=0"`
+	Page     int    `header:"X-Page-Size,default=20"`
+	Trace    string `header:"X-Trace-Id,optional"`
+}
+
+type cookieOnly struct {
+	Session string   `cookie:"session" validate:"required"`
+	Theme   string   `cookie:"theme,default=light"`
+	Flags   []string `cookie:"flag,optional"`
+}
+
+func TestParseHeadersConvertsAndDefaults(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("x-tenant-id", "42")
+
+	var req headerOnly
+	require.NoError(t, ParseHeaders(r, &req))
+	require.Equal(t, headerOnly{TenantID: 42, Page: 20}, req)
+}
+
+func TestParseHeadersValidates(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(validation.WithLocale(r.Context(), "en"))
+	r.Header.Set("X-Tenant-Id", "0")
+
+	var req headerOnly
+	err := ParseHeaders(r, &req)
+
+	var ve validation.ValidateError
+	require.True(t, stderrors.As(err, &ve))
+	require.Equal(t, "X-Tenant-Id", ve.Violations()[0].Name)
+}
+
+func TestParseHeadersWrapsConversionErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Tenant-Id", "abc")
+
+	var req headerOnly
+	err := ParseHeaders(r, &req)
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr))
+	require.Equal(t, errors.CodeInvalidRequest, httpErr.Code())
+}
+
+func TestParseCookies(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	r.AddCookie(&http.Cookie{Name: "flag", Value: "a"})
+	r.AddCookie(&http.Cookie{Name: "flag", Value: "b"})
+
+	var req cookieOnly
+	require.NoError(t, ParseCookies(r, &req))
+	require.Equal(t, cookieOnly{Session: "s3cr3t", Theme: "light", Flags: []string{"a", "b"}}, req)
+}
+
+func TestParseCookiesRequiresMissingCookie(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	var req cookieOnly
+	err := ParseCookies(r, &req)
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr))
+	require.Equal(t, errors.CodeInvalidRequest, httpErr.Code())
+}
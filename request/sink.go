@@ -0,0 +1,135 @@
+package request
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSink stores streamed uploads in a local directory. Files are created through an os.Root
+// handle, so neither the generated names nor symlinks below the directory can place a file
+// outside of it.
+type DirSink struct {
+	root   string
+	nameFn func(UploadInfo) string
+	perm   fs.FileMode
+}
+
+// DirSinkOption configures a DirSink.
+type DirSinkOption func(*DirSink)
+
+// WithUploadName sets how the name of a stored file, relative to the directory, is chosen. The
+// default is a random name keeping the extension of the client file name. Intermediate
+// directories must exist.
+func WithUploadName(fn func(UploadInfo) string) DirSinkOption {
+	return func(s *DirSink) {
+		s.nameFn = fn
+	}
+}
+
+// WithUploadPerm sets the permission bits of stored files, 0o644 by default.
+func WithUploadPerm(perm fs.FileMode) DirSinkOption {
+	return func(s *DirSink) {
+		s.perm = perm
+	}
+}
+
+// NewDirSink returns a DirSink writing into the existing directory root.
+func NewDirSink(root string, opts ...DirSinkOption) (*DirSink, error) {
+	if root == "" {
+		return nil, fmt.Errorf("upload root cannot be empty")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve upload root %s: %w", root, err)
+	}
+	stat, err := os.Stat(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("stat upload root %s: %w", absRoot, err)
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("upload root %s is not a directory", absRoot)
+	}
+
+	s := &DirSink{root: absRoot, nameFn: randomUploadName, perm: 0o644}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// Create creates the file for info below the directory. Existing files are never overwritten.
+func (s *DirSink) Create(_ context.Context, info UploadInfo) (UploadWriter, error) {
+	name := s.nameFn(info)
+	root, err := os.OpenRoot(s.root)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	file, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, s.perm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirUpload{sink: s, name: name, file: file}, nil
+}
+
+// dirUpload is a file being written by a DirSink.
+type dirUpload struct {
+	sink *DirSink
+	name string
+	file *os.File
+}
+
+func (u *dirUpload) Write(p []byte) (int, error) {
+	return u.file.Write(p)
+}
+
+// Commit flushes and closes the file and returns its name relative to the directory.
+func (u *dirUpload) Commit(context.Context) (string, error) {
+	if err := u.file.Sync(); err != nil {
+		_ = u.file.Close()
+		return "", err
+	}
+	if err := u.file.Close(); err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(u.name), nil
+}
+
+// Abort closes and removes the file.
+func (u *dirUpload) Abort(context.Context) error {
+	_ = u.file.Close()
+
+	root, err := os.OpenRoot(u.sink.root)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	return root.Remove(u.name)
+}
+
+// randomUploadName returns a random file name with the lower-cased extension of the client
+// file name, dropping extensions that are not plain alphanumerics.
+func randomUploadName(info UploadInfo) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+
+	ext := strings.ToLower(filepath.Ext(info.Filename))
+	invalid := func(r rune) bool { return (r < 'a' || r > 'z') && (r < '0' || r > '9') }
+	if len(ext) < 2 || strings.ContainsFunc(ext[1:], invalid) {
+		ext = ""
+	}
+
+	return hex.EncodeToString(buf) + ext
+}
@@ -0,0 +1,315 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+)
+
+// sniffLen is the number of leading bytes of a streamed file used to detect its content type.
+const sniffLen = 3072
+
+// UploadInfo describes a file part before it is stored.
+type UploadInfo struct {
+	// Field is the form field name of the part.
+	Field string
+	// Filename is the base name of the file as sent by the client. It is not trusted.
+	Filename string
+	// ContentType is the type sniffed from the first bytes of the file.
+	ContentType string
+	// Header holds the MIME headers of the part.
+	Header textproto.MIMEHeader
+}
+
+// UploadSink stores the files of a streamed upload, e.g. in a local directory (DirSink) or in
+// an S3-compatible object store.
+type UploadSink interface {
+	// Create opens the destination of a file. The content is written before Commit is called.
+	Create(ctx context.Context, info UploadInfo) (UploadWriter, error)
+}
+
+// UploadWriter receives the content of one uploaded file.
+type UploadWriter interface {
+	io.Writer
+	// Commit finishes a completely written file and returns where it was stored.
+	Commit(ctx context.Context) (location string, err error)
+	// Abort discards the file. It is called for partially written files and for committed
+	// files of a request that fails later on.
+	Abort(ctx context.Context) error
+}
+
+// UploadedFile describes a file stored by StreamMultipart.
+type UploadedFile struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+	// SHA256 and MD5 are the hex encoded digests of the content.
+	SHA256 string
+	MD5    string
+	// Location is the value returned by UploadWriter.Commit.
+	Location string
+}
+
+// StreamedForm is the result of StreamMultipart.
+type StreamedForm struct {
+	// Values holds the non-file fields of the form.
+	Values url.Values
+	// Files lists the stored files in the order they were sent.
+	Files []UploadedFile
+}
+
+// StreamMultipart reads a multipart/form-data request part by part and pipes each file to sink
+// without buffering it, computing its SHA-256 and MD5 digests on the way. When a part carries a
+// Content-Digest (sha-256 or md5) or Content-MD5 header, the computed digest must match it.
+//
+// WithMaxTotalSize, WithMaxFileSize and WithMaxFiles are enforced while streaming and answer
+// errors.CodeTooLarge; WithAllowedMimeTypes is checked on the first bytes of each file before
+// it reaches the sink. If anything fails, including the cancellation of the request context,
+// every file written so far is aborted, so files are only kept when the whole request succeeds.
+// Failing writes to the sink are reported as errors.CodeUnknown, not as a bad request.
+func StreamMultipart(r *http.Request, sink UploadSink, opts ...Option) (*StreamedForm, error) {
+	o := buildOptions(opts)
+	ctx := r.Context()
+	guard, err := o.prepareBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, wrapParseErr(err)
+	}
+
+	form := &StreamedForm{Values: url.Values{}}
+	var written []UploadWriter
+	if err = o.streamParts(ctx, mr, sink, form, &written); err != nil {
+		cleanupCtx := context.WithoutCancel(ctx)
+		for _, w := range written {
+			_ = w.Abort(cleanupCtx)
+		}
+
+		if tooLarge := guard.exceeded(); tooLarge != nil {
+			return nil, tooLarge
+		}
+		return nil, err
+	}
+
+	return form, nil
+}
+
+func (o options) streamParts(ctx context.Context, mr *multipart.Reader, sink UploadSink, form *StreamedForm,
+	written *[]UploadWriter) error {
+	valueBudget := int64(defaultMaxMemory)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return wrapStreamErr(ctx, err)
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(contextReader{ctx: ctx, r: part}, valueBudget+1))
+			if err != nil {
+				return wrapStreamErr(ctx, err)
+			}
+			valueBudget -= int64(len(value))
+			if valueBudget < 0 {
+				return errors.New(errors.CodeTooLarge, "form values are too large")
+			}
+
+			form.Values.Add(name, string(value))
+			continue
+		}
+
+		if o.maxFiles > 0 && len(form.Files) >= o.maxFiles {
+			return errors.New(errors.CodeTooLarge, fmt.Sprintf("too many files, at most %d allowed", o.maxFiles))
+		}
+
+		file, err := o.streamFile(ctx, part, sink, written)
+		if err != nil {
+			return err
+		}
+		form.Files = append(form.Files, file)
+	}
+}
+
+// streamFile stores one file part in sink. The writer is added to written as soon as it exists
+// so that it is aborted if this or a later part fails.
+func (o options) streamFile(ctx context.Context, part *multipart.Part, sink UploadSink,
+	written *[]UploadWriter) (UploadedFile, error) {
+	src := io.Reader(contextReader{ctx: ctx, r: part})
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && !stderrors.Is(err, io.ErrUnexpectedEOF) {
+		return UploadedFile{}, wrapStreamErr(ctx, err)
+	}
+	head = head[:n]
+
+	info := UploadInfo{
+		Field:       part.FormName(),
+		Filename:    part.FileName(),
+		ContentType: mimetype.Detect(head).String(),
+		Header:      part.Header,
+	}
+	if len(o.allowedMimeTypes) > 0 && !validation.MatchContent(head, o.allowedMimeTypes...) {
+		return UploadedFile{}, errors.New(errors.CodeUnsupportedMediaType,
+			fmt.Sprintf("file %q of field %s has an unsupported type", info.Filename, info.Field))
+	}
+
+	expected, err := expectedDigests(part.Header)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	w, err := sink.Create(ctx, info)
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("create upload %q: %w", info.Filename, err)
+	}
+	*written = append(*written, w)
+
+	src = io.MultiReader(bytes.NewReader(head), src)
+	if o.maxFileSize > 0 {
+		src = io.LimitReader(src, o.maxFileSize+1)
+	}
+
+	sha, sum := sha256.New(), md5.New()
+	dst := &sinkWriter{w: w}
+	size, err := io.Copy(io.MultiWriter(dst, sha, sum), src)
+	if dst.err != nil {
+		return UploadedFile{}, errors.Wrap(fmt.Errorf("write upload %q: %w", info.Filename, dst.err),
+			errors.CodeUnknown)
+	}
+	if err != nil {
+		return UploadedFile{}, wrapStreamErr(ctx, err)
+	}
+	if o.maxFileSize > 0 && size > o.maxFileSize {
+		return UploadedFile{}, errors.New(errors.CodeTooLarge,
+			fmt.Sprintf("file %q of field %s exceeds %d bytes", info.Filename, info.Field, o.maxFileSize))
+	}
+
+	digests := map[string]hash.Hash{"sha-256": sha, "md5": sum}
+	for alg, want := range expected {
+		if !bytes.Equal(digests[alg].Sum(nil), want) {
+			return UploadedFile{}, errors.New(errors.CodeInvalidRequest,
+				fmt.Sprintf("%s digest of file %q does not match", alg, info.Filename))
+		}
+	}
+
+	location, err := w.Commit(ctx)
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("commit upload %q: %w", info.Filename, err)
+	}
+
+	return UploadedFile{
+		Field:       info.Field,
+		Filename:    info.Filename,
+		ContentType: info.ContentType,
+		Size:        size,
+		SHA256:      hex.EncodeToString(sha.Sum(nil)),
+		MD5:         hex.EncodeToString(sum.Sum(nil)),
+		Location:    location,
+	}, nil
+}
+
+// expectedDigests returns the digests announced by the Content-Digest (RFC 9530) and
+// Content-MD5 headers of a part, keyed by algorithm. Unsupported algorithms are ignored.
+func expectedDigests(header textproto.MIMEHeader) (map[string][]byte, error) {
+	expected := map[string][]byte{}
+	if value := header.Get("Content-MD5"); value != "" {
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errors.New(errors.CodeInvalidRequest, "malformed Content-MD5 header")
+		}
+		expected["md5"] = digest
+	}
+
+	for _, value := range header.Values("Content-Digest") {
+		for _, item := range strings.Split(value, ",") {
+			alg, encoded, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				return nil, errors.New(errors.CodeInvalidRequest, "malformed Content-Digest header")
+			}
+
+			alg = strings.ToLower(strings.TrimSpace(alg))
+			if alg != "sha-256" && alg != "md5" {
+				continue
+			}
+
+			digest, err := base64.StdEncoding.DecodeString(strings.Trim(strings.TrimSpace(encoded), ":"))
+			if err != nil {
+				return nil, errors.New(errors.CodeInvalidRequest, "malformed Content-Digest header")
+			}
+			expected[alg] = digest
+		}
+	}
+
+	return expected, nil
+}
+
+// wrapStreamErr reports the cancellation of ctx as such and other read failures like
+// wrapMultipartErr.
+func wrapStreamErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return wrapMultipartErr(err)
+}
+
+// sinkWriter remembers the write error of an UploadWriter, so that a failing sink is reported
+// as a server error rather than blamed on the request.
+type sinkWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (s *sinkWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	if err != nil {
+		s.err = err
+	}
+
+	return n, err
+}
+
+// contextReader stops reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(p)
+}
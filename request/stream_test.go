@@ -0,0 +1,232 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type streamPart struct {
+	field, name string
+	content     []byte
+	header      map[string]string
+}
+
+func TestStreamMultipartStoresFiles(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewDirSink(dir)
+	require.NoError(t, err)
+
+	content := []byte("hello, streamed world")
+	sha := sha256.Sum256(content)
+	r := newStreamRequest(t, map[string]string{"title": "report"},
+		streamPart{field: "doc", name: "report.TXT", content: content,
+			header: map[string]string{"Content-Digest": "sha-256=:" + base64.StdEncoding.EncodeToString(sha[:]) + ":"}})
+
+	form, err := StreamMultipart(r, sink)
+	require.NoError(t, err)
+	require.Equal(t, "report", form.Values.Get("title"))
+	require.Len(t, form.Files, 1)
+
+	file := form.Files[0]
+	md := md5.Sum(content)
+	require.Equal(t, "doc", file.Field)
+	require.Equal(t, "report.TXT", file.Filename)
+	require.Equal(t, int64(len(content)), file.Size)
+	require.Equal(t, hex.EncodeToString(sha[:]), file.SHA256)
+	require.Equal(t, hex.EncodeToString(md[:]), file.MD5)
+	require.Equal(t, ".txt", filepath.Ext(file.Location))
+
+	stored, err := os.ReadFile(filepath.Join(dir, file.Location))
+	require.NoError(t, err)
+	require.Equal(t, content, stored)
+}
+
+func TestStreamMultipartCleansUpOnFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		part streamPart
+		code errors.HttpCode
+	}{
+		{
+			name: "digest mismatch",
+			part: streamPart{field: "b", name: "b.txt", content: []byte("b"),
+				header: map[string]string{"Content-MD5": base64.StdEncoding.EncodeToString(make([]byte, md5.Size))}},
+			code: errors.CodeInvalidRequest,
+		},
+		{
+			name: "file size",
+			opts: []Option{WithMaxFileSize(4)},
+			part: streamPart{field: "b", name: "b.txt", content: []byte("too large")},
+			code: errors.CodeTooLarge,
+		},
+		{
+			name: "file count",
+			opts: []Option{WithMaxFiles(1)},
+			part: streamPart{field: "b", name: "b.txt", content: []byte("b")},
+			code: errors.CodeTooLarge,
+		},
+		{
+			name: "mime type",
+			opts: []Option{WithAllowedMimeTypes("image/*")},
+			part: streamPart{field: "b", name: "b.txt", content: []byte("b")},
+			code: errors.CodeUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			sink, err := NewDirSink(dir)
+			require.NoError(t, err)
+
+			r := newStreamRequest(t, nil,
+				streamPart{field: "a", name: "a.png", content: pngHeader},
+				tt.part)
+			_, err = StreamMultipart(r, sink, tt.opts...)
+
+			var httpErr errors.HttpError
+			require.True(t, stderrors.As(err, &httpErr), "got %v", err)
+			require.Equal(t, tt.code, httpErr.Code())
+			requireEmptyDir(t, dir)
+		})
+	}
+}
+
+func TestStreamMultipartReportsSinkFailure(t *testing.T) {
+	sink := &failingSink{}
+	r := newStreamRequest(t, nil, streamPart{field: "a", name: "a.txt", content: []byte("a")})
+	_, err := StreamMultipart(r, sink)
+
+	var codeErr *errors.CodeError
+	require.True(t, stderrors.As(err, &codeErr), "got %v", err)
+	require.Equal(t, errors.CodeUnknown, codeErr.Code())
+	require.Equal(t, http.StatusInternalServerError, codeErr.Status())
+	require.ErrorIs(t, err, errDiskFull)
+	require.True(t, sink.aborted)
+}
+
+func TestStreamMultipartStopsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewDirSink(dir)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := newStreamRequest(t, nil, streamPart{field: "a", name: "a.txt", content: bytes.Repeat([]byte("a"), 16<<10)})
+	r = r.WithContext(ctx)
+	r.Body = io.NopCloser(&cancelingReader{r: r.Body, cancel: cancel})
+
+	_, err = StreamMultipart(r, sink)
+	require.ErrorIs(t, err, context.Canceled)
+	requireEmptyDir(t, dir)
+}
+
+func TestDirSinkStaysInsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewDirSink(dir, WithUploadName(func(info UploadInfo) string {
+		return "../" + info.Filename
+	}))
+	require.NoError(t, err)
+
+	_, err = sink.Create(context.Background(), UploadInfo{Filename: "escape.txt"})
+	require.Error(t, err)
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "escape.txt"))
+	require.True(t, os.IsNotExist(err))
+}
+
+// cancelingReader cancels the request context once the file part is being streamed.
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+	read   int
+}
+
+func (c *cancelingReader) Read(p []byte) (int, error) {
+	if c.read > 8<<10 {
+		c.cancel()
+	}
+
+	n, err := c.r.Read(p[:min(len(p), 1<<10)])
+	c.read += n
+	return n, err
+}
+
+func requireEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func newStreamRequest(t *testing.T, values map[string]string, parts ...streamPart) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range values {
+		require.NoError(t, mw.WriteField(name, value))
+	}
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+p.field+`"; filename="`+p.name+`"`)
+		h.Set("Content-Type", "application/octet-stream")
+		for k, v := range p.header {
+			h.Set(k, v)
+		}
+
+		w, err := mw.CreatePart(h)
+		require.NoError(t, err)
+		_, err = w.Write(p.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+var errDiskFull = stderrors.New("disk full")
+
+// failingSink creates writers whose writes fail.
+type failingSink struct {
+	aborted bool
+}
+
+func (s *failingSink) Create(context.Context, UploadInfo) (UploadWriter, error) {
+	return failingWriter{sink: s}, nil
+}
+
+type failingWriter struct {
+	sink *failingSink
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errDiskFull
+}
+
+func (failingWriter) Commit(context.Context) (string, error) {
+	return "", nil
+}
+
+func (w failingWriter) Abort(context.Context) error {
+	w.sink.aborted = true
+	return nil
+}
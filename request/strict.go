@@ -0,0 +1,228 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/starme/go-zero/httpx/validation"
+)
+
+const jsonKey = "json"
+
+// maxJSONBodyLen caps the JSON body read by strict decoding when no body limit is set, like
+// the limit go-zero applies when decoding JSON bodies.
+const maxJSONBodyLen = 8 << 20
+
+// Rules reported by strict JSON decoding, translated like validation tags.
+const (
+	RuleUnknownField = "unknown_field"
+	RuleDuplicateKey = "duplicate_key"
+	RuleTrailingData = "trailing_data"
+)
+
+// WithStrictJSON makes Parse and ParseJsonBody reject JSON bodies with fields v does not
+// declare, keys repeated within an object, or data after the JSON value. Each problem is
+// reported as a validation.FieldViolation whose Name is the JSON path, e.g. "items[0].nmae",
+// and whose Rule is RuleUnknownField, RuleDuplicateKey or RuleTrailingData.
+func WithStrictJSON() Option {
+	return func(o *options) {
+		o.strictJSON = true
+	}
+}
+
+// checkStrictJSON reads the JSON body of r, puts it back for decoding, and reports the
+// violations of strict decoding as a validation.ValidateError. Bodies that are not valid JSON
+// are left for the decoder to reject. At most the body limit, or maxJSONBodyLen without one,
+// is read; larger bodies are reported as errors.CodeTooLarge.
+func (o options) checkStrictJSON(r *http.Request, guard *bodyGuard, v any) error {
+	if !o.strictJSON || !withJsonBody(r) {
+		return nil
+	}
+
+	limit := o.bodyLimit()
+	if limit <= 0 {
+		limit = maxJSONBodyLen
+	}
+	data, err := io.ReadAll(guard.limit(r.Body, "JSON body", limit))
+	if err != nil {
+		return guard.wrap(err)
+	}
+	r.Body = readCloser{Reader: bytes.NewReader(data), Closer: r.Body}
+
+	violations := strictJSONViolations(data, reflect.TypeOf(v))
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return o.violations(r.Context(), violations)
+}
+
+// violations translates violations with the configured validator.
+func (o options) violations(ctx context.Context, violations []validation.FieldViolation) validation.ValidateError {
+	v := o.validator
+	if v == nil {
+		v = validation.Default()
+	}
+
+	ve := make(validation.ValidateError, 0, len(violations))
+	for _, violation := range violations {
+		ve = append(ve, v.Violation(ctx, violation))
+	}
+
+	return ve
+}
+
+// strictJSONViolations walks data alongside t and lists unknown fields, duplicate keys and
+// trailing data. It stops at the first syntax error.
+func strictJSONViolations(data []byte, t reflect.Type) []validation.FieldViolation {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	w := &strictWalker{dec: dec}
+	if err := w.value(t, ""); err != nil {
+		return w.violations
+	}
+
+	if len(bytes.TrimSpace(data[dec.InputOffset():])) > 0 {
+		w.violations = append(w.violations, validation.FieldViolation{Rule: RuleTrailingData})
+	}
+
+	return w.violations
+}
+
+type strictWalker struct {
+	dec        *json.Decoder
+	violations []validation.FieldViolation
+}
+
+func (w *strictWalker) report(path, rule string) {
+	w.violations = append(w.violations, validation.FieldViolation{Name: path, Rule: rule})
+}
+
+// value consumes the next JSON value, which decodes into t; a nil t accepts anything.
+func (w *strictWalker) value(t reflect.Type, path string) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		return w.object(t, path)
+	case json.Delim('['):
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+
+		for i := 0; w.dec.More(); i++ {
+			if err = w.value(elem, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+		return err
+	default:
+		return nil
+	}
+}
+
+func (w *strictWalker) object(t reflect.Type, path string) error {
+	var fields map[string]reflect.Type
+	var elem reflect.Type
+	switch {
+	case t != nil && t.Kind() == reflect.Struct:
+		fields = jsonFields(t)
+	case t != nil && t.Kind() == reflect.Map:
+		elem = t.Elem()
+	}
+
+	seen := map[string]bool{}
+	for w.dec.More() {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := tok.(string)
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		if seen[key] {
+			w.report(keyPath, RuleDuplicateKey)
+		}
+		seen[key] = true
+
+		next := elem
+		if fields != nil {
+			fieldType, ok := fields[key]
+			if !ok {
+				w.report(keyPath, RuleUnknownField)
+			}
+			next = fieldType
+		}
+
+		if err = w.value(next, keyPath); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.dec.Token()
+	return err
+}
+
+// jsonFields maps the JSON keys of struct t to the field types, following the rules of the
+// go-zero decoder: fields without tags use their Go name, tagged fields without a json tag are
+// not decoded, and anonymous struct fields are flattened.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(jsonKey)
+		if !ok && len(field.Tag) > 0 {
+			continue
+		}
+
+		name := strings.TrimSpace(strings.SplitN(tag, ",", 2)[0])
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for key, embedded := range jsonFields(fieldType) {
+				fields[key] = embedded
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+// withJsonBody mirrors the check go-zero uses before decoding a JSON body.
+func withJsonBody(r *http.Request) bool {
+	return r.ContentLength > 0 && strings.Contains(r.Header.Get("Content-Type"), "application/json")
+}
@@ -0,0 +1,90 @@
+package request
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+	"github.com/stretchr/testify/require"
+)
+
+type orderItem struct {
+	SKU   string `json:"sku"`
+	Count int    `json:"count,optional"`
+}
+
+type orderMeta struct {
+	Source string `json:"source,optional"`
+}
+
+type orderRequest struct {
+	orderMeta
+	Items  []orderItem       `json:"items"`
+	Labels map[string]string `json:"labels,optional"`
+	Extra  map[string]any    `json:"extra,optional"`
+	Origin *orderMeta        `json:"origin,optional"`
+}
+
+func TestStrictJSONAcceptsKnownFields(t *testing.T) {
+	body := `{"source":"web","items":[{"sku":"a","count":2}],"labels":{"any":"key"},"extra":{"free":1},"origin":{"source":"app"}}`
+
+	var req orderRequest
+	require.NoError(t, ParseJsonBody(newJsonRequest(body), &req, WithStrictJSON()))
+	require.Equal(t, "web", req.Source)
+	require.Equal(t, 2, req.Items[0].Count)
+}
+
+func TestStrictJSONReportsViolations(t *testing.T) {
+	body := `{"items":[{"sku":"a","cuont":2}],"items":[],"colour":"red"} {}`
+	r := newJsonRequest(body)
+	r = r.WithContext(validation.WithLocale(r.Context(), "en"))
+
+	var req orderRequest
+	err := ParseJsonBody(r, &req, WithStrictJSON())
+
+	var ve validation.ValidateError
+	require.True(t, stderrors.As(err, &ve), "got %v", err)
+	require.Equal(t, errors.CodeValidation, ve.Code())
+	require.Equal(t, []validation.FieldViolation{
+		{Name: "items[0].cuont", Rule: RuleUnknownField, Message: "items[0].cuont is not a known field"},
+		{Name: "items", Rule: RuleDuplicateKey, Message: "items must not be specified more than once"},
+		{Name: "colour", Rule: RuleUnknownField, Message: "colour is not a known field"},
+		{Rule: RuleTrailingData, Message: "the request body must contain a single JSON value"},
+	}, ve.Violations())
+}
+
+func TestStrictJSONTranslates(t *testing.T) {
+	r := newJsonRequest(`{"items":[],"colour":"red"}`)
+	r = r.WithContext(validation.WithLocale(r.Context(), "zh"))
+
+	var req orderRequest
+	err := Parse(r, &req, WithStrictJSON())
+	require.EqualError(t, err, "colour是未知字段")
+}
+
+func TestStrictJSONLeavesSyntaxErrorsToDecoder(t *testing.T) {
+	var req orderRequest
+	err := ParseJsonBody(newJsonRequest(`{"items":`), &req, WithStrictJSON())
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr))
+	require.Equal(t, errors.CodeInvalidRequest, httpErr.Code())
+}
+
+func TestStrictJSONLimitsBody(t *testing.T) {
+	body := `{"items":[],"extra":{"blob":"` + strings.Repeat("a", maxJSONBodyLen) + `"}}`
+
+	var req orderRequest
+	err := ParseJsonBody(newJsonRequest(body), &req, WithStrictJSON())
+
+	var httpErr errors.HttpError
+	require.True(t, stderrors.As(err, &httpErr), "got %v", err)
+	require.Equal(t, errors.CodeTooLarge, httpErr.Code())
+}
+
+func TestNonStrictJSONIgnoresUnknownFields(t *testing.T) {
+	var req orderRequest
+	require.NoError(t, ParseJsonBody(newJsonRequest(`{"items":[],"colour":"red"}`), &req))
+}
@@ -0,0 +1,292 @@
+package response
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ArchiveFormat selects the container format produced by ServeArchive.
+type ArchiveFormat int
+
+const (
+	// ArchiveZip streams a zip archive with deflate-compressed entries.
+	ArchiveZip ArchiveFormat = iota
+	// ArchiveTarGz streams a gzip-compressed tar archive.
+	ArchiveTarGz
+)
+
+// ArchiveEntry describes one file of an archive download.
+type ArchiveEntry struct {
+	// Path is resolved below the download root exactly like a single-file download.
+	Path string
+	// Name is the slash-separated path of the entry inside the archive.
+	// It defaults to the base name of Path.
+	Name string
+}
+
+// ServeArchive streams the files at entries as a single archive named name.
+func ServeArchive(w http.ResponseWriter, r *http.Request, name string, format ArchiveFormat,
+	entries []ArchiveEntry, opts ...ServeOption) {
+	DefaultDownloader().ServeArchive(w, r, name, format, entries, opts...)
+}
+
+// ServeArchive streams the files at entries as a single archive named name without buffering
+// it on disk. Every entry is checked against the root and policy of the Downloader before the
+// response starts, so missing or forbidden files are reported as a regular download error, and
+// so is a request context canceled by then. Failures after streaming has begun, such as a file disappearing or the request context being
+// canceled, are logged and leave the archive truncated so the client cannot mistake it for a
+// complete download.
+func (d *Downloader) ServeArchive(w http.ResponseWriter, r *http.Request, name string, format ArchiveFormat,
+	entries []ArchiveEntry, opts ...ServeOption) {
+	ctx := r.Context()
+
+	names, err := d.prepareArchive(format, entries)
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, err))
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		ErrorCtx(ctx, w, err)
+		return
+	}
+
+	contentType := "application/zip"
+	if format == ArchiveTarGz {
+		contentType = "application/gzip"
+	}
+	setDownloadHeaders(w, name, contentType, buildServeOptions(opts))
+	w.WriteHeader(http.StatusOK)
+
+	if format == ArchiveTarGz {
+		err = d.writeTarGz(ctx, w, entries, names)
+	} else {
+		err = d.writeZip(ctx, w, entries, names)
+	}
+	if err != nil {
+		logx.WithContext(ctx).Errorf("download archive %s: %v", name, err)
+	}
+}
+
+// prepareArchive validates the format and entries and returns the name of each entry inside
+// the archive.
+func (d *Downloader) prepareArchive(format ArchiveFormat, entries []ArchiveEntry) ([]string, error) {
+	if format != ArchiveZip && format != ArchiveTarGz {
+		return nil, fmt.Errorf("unsupported archive format %d", format)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("archive has no entries")
+	}
+
+	names := make([]string, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		entryName, err := archiveEntryName(entry)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[entryName]; ok {
+			return nil, fmt.Errorf("duplicate archive entry %s", entryName)
+		}
+		seen[entryName] = struct{}{}
+		names[i] = entryName
+
+		file, _, err := d.openArchiveEntry(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		file.Close()
+	}
+
+	return names, nil
+}
+
+// archiveEntryName returns a clean relative name for entry that cannot escape the
+// extraction directory of the client.
+func archiveEntryName(entry ArchiveEntry) (string, error) {
+	name := entry.Name
+	if name == "" {
+		name = filepath.Base(entry.Path)
+	}
+
+	// Windows extractors treat backslashes as separators, so they must not smuggle in "..".
+	name = strings.ReplaceAll(filepath.ToSlash(name), `\`, "/")
+	if strings.HasPrefix(name, "//") || hasDriveLetter(name) {
+		return "", fmt.Errorf("invalid archive entry name %q", entry.Name)
+	}
+
+	name = path.Clean(strings.TrimLeft(name, "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("invalid archive entry name %q", entry.Name)
+	}
+
+	return name, nil
+}
+
+// hasDriveLetter reports whether name starts with a Windows volume such as "C:".
+func hasDriveLetter(name string) bool {
+	if len(name) < 2 || name[1] != ':' {
+		return false
+	}
+
+	c := name[0] | 0x20
+	return c >= 'a' && c <= 'z'
+}
+
+// openArchiveEntry opens a regular file below the root of the Downloader and enforces the
+// extension, size and content type policy on it, like a single download.
+func (d *Downloader) openArchiveEntry(path string) (*os.File, os.FileInfo, error) {
+	file, err := d.open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := d.checkArchiveEntry(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, stat, nil
+}
+
+func (d *Downloader) checkArchiveEntry(file *os.File) (os.FileInfo, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("not a regular file")
+	}
+	if err = d.check(stat.Name(), stat.Size()); err != nil {
+		return nil, err
+	}
+
+	contentType, err := d.detectContentType(stat.Name(), file)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.checkContentType(contentType); err != nil {
+		return nil, err
+	}
+
+	return stat, nil
+}
+
+func (d *Downloader) writeZip(ctx context.Context, w io.Writer, entries []ArchiveEntry, names []string) error {
+	zw := zip.NewWriter(w)
+
+	for i, entry := range entries {
+		err := d.writeArchiveEntry(ctx, entry.Path, func(file *os.File, stat os.FileInfo) error {
+			header, err := zip.FileInfoHeader(stat)
+			if err != nil {
+				return err
+			}
+			header.Name = names[i]
+			header.Method = zip.Deflate
+
+			entryWriter, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+
+			return copyEntry(ctx, entryWriter, file, stat.Size())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The central directory is only written once every entry succeeded; an aborted
+	// stream therefore never looks like a valid archive.
+	return zw.Close()
+}
+
+func (d *Downloader) writeTarGz(ctx context.Context, w io.Writer, entries []ArchiveEntry, names []string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for i, entry := range entries {
+		err := d.writeArchiveEntry(ctx, entry.Path, func(file *os.File, stat os.FileInfo) error {
+			header, err := tar.FileInfoHeader(stat, "")
+			if err != nil {
+				return err
+			}
+			header.Name = names[i]
+
+			if err = tw.WriteHeader(header); err != nil {
+				return err
+			}
+
+			return copyEntry(ctx, tw, file, stat.Size())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// writeArchiveEntry reopens the file at path and hands it to write, so the archive reflects
+// the file as it exists when its entry is streamed.
+func (d *Downloader) writeArchiveEntry(ctx context.Context, path string,
+	write func(*os.File, os.FileInfo) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	file, stat, err := d.openArchiveEntry(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err = write(file, stat); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// copyEntry copies exactly size bytes from src, failing if the file shrank while streaming
+// or the context was canceled.
+func copyEntry(ctx context.Context, dst io.Writer, src io.Reader, size int64) error {
+	n, err := io.Copy(dst, io.LimitReader(contextReader{ctx: ctx, r: src}, size))
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("file changed while streaming: wrote %d of %d bytes", n, size)
+	}
+
+	return nil
+}
+
+// contextReader stops reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(p)
+}
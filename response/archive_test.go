@@ -0,0 +1,211 @@
+package response
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeArchiveZip(t *testing.T) {
+	root := setupArchiveFiles(t, map[string]string{
+		"invoices/2026-10/a.pdf": "invoice a",
+		"invoices/2026-10/b.pdf": "invoice b",
+	})
+
+	d, err := NewDownloader(root)
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	d.ServeArchive(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "invoices.zip", ArchiveZip, []ArchiveEntry{
+		{Path: "invoices/2026-10/a.pdf"},
+		{Path: "invoices/2026-10/b.pdf", Name: "october/b.pdf"},
+	})
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/zip" {
+		t.Fatalf("unexpected Content-Type: %s", got)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open entry: %v", err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read entry: %v", err)
+		}
+		got[file.Name] = string(data)
+	}
+
+	if got["a.pdf"] != "invoice a" || got["october/b.pdf"] != "invoice b" || len(got) != 2 {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+}
+
+func TestServeArchiveTarGz(t *testing.T) {
+	root := setupArchiveFiles(t, map[string]string{"a.txt": "first", "b.txt": "second"})
+
+	d, err := NewDownloader(root)
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	d.ServeArchive(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "batch.tar.gz", ArchiveTarGz,
+		[]ArchiveEntry{{Path: "a.txt"}, {Path: "b.txt"}})
+
+	gz, err := gzip.NewReader(recorder.Body)
+	if err != nil {
+		t.Fatalf("open gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		names = append(names, header.Name)
+	}
+
+	if strings.Join(names, ",") != "a.txt,b.txt" {
+		t.Fatalf("unexpected entries: %v", names)
+	}
+}
+
+func TestServeArchiveRejectsInvalidEntries(t *testing.T) {
+	root := setupArchiveFiles(t, map[string]string{"a.txt": "first"})
+
+	d, err := NewDownloader(root)
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		entries []ArchiveEntry
+		status  int
+	}{
+		{name: "missing file", entries: []ArchiveEntry{{Path: "a.txt"}, {Path: "missing.txt"}}, status: http.StatusNotFound},
+		{name: "escaping path", entries: []ArchiveEntry{{Path: "../a.txt"}}, status: http.StatusForbidden},
+		{name: "escaping entry name", entries: []ArchiveEntry{{Path: "a.txt", Name: "../../a.txt"}}, status: http.StatusBadRequest},
+		{name: "backslash entry name", entries: []ArchiveEntry{{Path: "a.txt", Name: `..\..\a.txt`}}, status: http.StatusBadRequest},
+		{name: "drive letter entry name", entries: []ArchiveEntry{{Path: "a.txt", Name: `C:\a.txt`}}, status: http.StatusBadRequest},
+		{name: "UNC entry name", entries: []ArchiveEntry{{Path: "a.txt", Name: `\\server\share\a.txt`}}, status: http.StatusBadRequest},
+		{name: "duplicate entry name", entries: []ArchiveEntry{{Path: "a.txt"}, {Path: "a.txt"}}, status: http.StatusBadRequest},
+		{name: "no entries", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			d.ServeArchive(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "batch.zip", ArchiveZip, tt.entries)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestServeArchiveEnforcesAllowedContentTypes(t *testing.T) {
+	root := setupArchiveFiles(t, map[string]string{
+		"a.pdf": "%PDF-1.4\n%real pdf",
+		"b.pdf": "<!DOCTYPE html><html><body>not a pdf</body></html>",
+	})
+
+	d, err := NewDownloader(root, WithAllowedContentTypes("application/pdf"))
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	d.ServeArchive(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "batch.zip", ArchiveZip,
+		[]ArchiveEntry{{Path: "a.pdf"}, {Path: "b.pdf"}})
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	d.ServeArchive(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "batch.zip", ArchiveZip,
+		[]ArchiveEntry{{Path: "a.pdf"}})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
+
+func TestServeArchiveStopsOnCanceledContext(t *testing.T) {
+	root := setupArchiveFiles(t, map[string]string{"a.txt": "first"})
+
+	d, err := NewDownloader(root)
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	d.ServeArchive(recorder, req, "batch.zip", ArchiveZip, []ArchiveEntry{{Path: "a.txt"}})
+
+	if recorder.Code != 499 {
+		t.Fatalf("expected %d, got %d", 499, recorder.Code)
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != "" {
+		t.Fatalf("unexpected Content-Disposition: %s", disposition)
+	}
+	if _, err = zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len())); err == nil {
+		t.Fatalf("expected canceled archive to be incomplete")
+	}
+}
+
+func TestCopyEntryDetectsShrunkFile(t *testing.T) {
+	err := copyEntry(context.Background(), io.Discard, strings.NewReader("short"), 10)
+	if err == nil {
+		t.Fatalf("expected error when the file shrinks while streaming")
+	}
+}
+
+func setupArchiveFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		target := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatalf("create directories: %v", err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	return root
+}
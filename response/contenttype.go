@@ -0,0 +1,100 @@
+package response
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// sniffLen is the number of leading bytes inspected when detecting a content type.
+const sniffLen = 3072
+
+// ContentTypeFunc overrides content type detection for a download. It receives the file name
+// and up to the first 3072 bytes of content, and returns an empty string to fall back to detection.
+type ContentTypeFunc func(name string, head []byte) string
+
+// WithContentTypeFunc installs a hook that decides the Content-Type before detection runs.
+func WithContentTypeFunc(fn ContentTypeFunc) DownloaderOption {
+	return func(d *Downloader) {
+		d.contentTypeFn = fn
+	}
+}
+
+// WithAllowedContentTypes restricts downloads to the given media types. Entries may use a
+// wildcard subtype such as "image/*"; parameters like charset are ignored when matching.
+func WithAllowedContentTypes(types ...string) DownloaderOption {
+	return func(d *Downloader) {
+		for _, t := range types {
+			if t = normalizeMediaType(t); t != "" {
+				d.allowedContentTypes = append(d.allowedContentTypes, t)
+			}
+		}
+	}
+}
+
+// detectContentType picks the Content-Type for content, preferring the override hook, then
+// the sniffed type, then the type registered for the file extension, then the default type.
+// content is rewound to the start before returning.
+func (d *Downloader) detectContentType(name string, content io.ReadSeeker) (string, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("read content: %w", err)
+	}
+	if _, err = content.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("seek content: %w", err)
+	}
+
+	return d.contentType(name, head[:n]), nil
+}
+
+func (d *Downloader) contentType(name string, head []byte) string {
+	if d.contentTypeFn != nil {
+		if contentType := d.contentTypeFn(name, head); contentType != "" {
+			return contentType
+		}
+	}
+
+	sniffed := mimetype.Detect(head)
+	if sniffed.Is(defaultDownloadContentType) || sniffed.Is("text/plain") {
+		byExt := mime.TypeByExtension(filepath.Ext(name))
+		if byExt != "" && normalizeMediaType(byExt) != defaultDownloadContentType {
+			return byExt
+		}
+	}
+	if sniffed.Is(defaultDownloadContentType) {
+		return d.defaultContentType
+	}
+
+	return sniffed.String()
+}
+
+// checkContentType enforces the content type allow-list of the Downloader.
+func (d *Downloader) checkContentType(contentType string) error {
+	if len(d.allowedContentTypes) == 0 {
+		return nil
+	}
+
+	mediaType := normalizeMediaType(contentType)
+	for _, allowed := range d.allowedContentTypes {
+		if allowed == mediaType {
+			return nil
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("content type %q is not allowed: %w", mediaType, fs.ErrPermission)
+}
+
+// normalizeMediaType strips parameters and lowercases a media type.
+func normalizeMediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
@@ -0,0 +1,114 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// EmptyData selects how the data field of a response without data is written.
+type EmptyData int
+
+const (
+	// EmptyNull writes "data": null.
+	EmptyNull EmptyData = iota
+	// EmptyObject writes "data": {}.
+	EmptyObject
+	// EmptyArray writes "data": [].
+	EmptyArray
+)
+
+var (
+	emptyData       = EmptyNull
+	legacyArrayData bool
+	dataConfMu      sync.RWMutex
+)
+
+// SetEmptyData selects how responses without data, including error responses, represent the
+// data field. The default is EmptyNull.
+func SetEmptyData(e EmptyData) {
+	dataConfMu.Lock()
+	emptyData = e
+	dataConfMu.Unlock()
+}
+
+// SetLegacyArrayData restores the former Success behavior for services whose clients rely on
+// it: data is always the array of the values passed to Success, and missing data is written
+// as [].
+func SetLegacyArrayData(enabled bool) {
+	dataConfMu.Lock()
+	legacyArrayData = enabled
+	dataConfMu.Unlock()
+}
+
+// SuccessList writes a success response whose data is items, written as [] when items is nil.
+func SuccessList[T any](w http.ResponseWriter, items []T) {
+	SuccessListCtx(context.Background(), w, items)
+}
+
+// SuccessListCtx writes a list response using the provided context.
+func SuccessListCtx[T any](ctx context.Context, w http.ResponseWriter, items []T) {
+	if items == nil {
+		items = []T{}
+	}
+
+	responseCtx(ctx, w, http.StatusOK, 0, items, nil)
+}
+
+// successData returns the data written for the values passed to Success: nothing, a single
+// value, or the array of several values.
+func successData(data []any) any {
+	dataConfMu.RLock()
+	legacy := legacyArrayData
+	dataConfMu.RUnlock()
+
+	switch {
+	case legacy:
+		return data
+	case len(data) == 0:
+		return nil
+	case len(data) == 1:
+		return data[0]
+	default:
+		return data
+	}
+}
+
+// formatData replaces missing data, a nil value of any kind, with the configured empty
+// representation.
+func formatData(data any) any {
+	if !isNil(data) {
+		return data
+	}
+
+	dataConfMu.RLock()
+	defer dataConfMu.RUnlock()
+
+	if legacyArrayData {
+		return []any{}
+	}
+
+	switch emptyData {
+	case EmptyObject:
+		return struct{}{}
+	case EmptyArray:
+		return []any{}
+	default:
+		return nil
+	}
+}
+
+func isNil(data any) bool {
+	if data == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
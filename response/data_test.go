@@ -0,0 +1,80 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestSuccessDataShape(t *testing.T) {
+	cases := []struct {
+		name  string
+		write func(*httptest.ResponseRecorder)
+		want  string
+	}{
+		{name: "single value", write: func(w *httptest.ResponseRecorder) { Success(w, user{Name: "a"}) }, want: `{"name":"a"}`},
+		{name: "several values", write: func(w *httptest.ResponseRecorder) { Success(w, 1, "two") }, want: `[1,"two"]`},
+		{name: "nil pointer", write: func(w *httptest.ResponseRecorder) { Success(w, (*user)(nil)) }, want: `null`},
+		{name: "list", write: func(w *httptest.ResponseRecorder) { SuccessList(w, []user{{Name: "a"}}) }, want: `[{"name":"a"}]`},
+		{name: "nil list", write: func(w *httptest.ResponseRecorder) { SuccessList[user](w, nil) }, want: `[]`},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.write(recorder)
+
+			if got := dataField(t, recorder); got != tt.want {
+				t.Fatalf("expected data %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetEmptyData(t *testing.T) {
+	t.Cleanup(func() { SetEmptyData(EmptyNull) })
+
+	for mode, want := range map[EmptyData]string{EmptyNull: `null`, EmptyObject: `{}`, EmptyArray: `[]`} {
+		SetEmptyData(mode)
+
+		recorder := httptest.NewRecorder()
+		Success(recorder)
+		if got := dataField(t, recorder); got != want {
+			t.Fatalf("mode %d: expected data %s, got %s", mode, want, got)
+		}
+	}
+}
+
+func TestSetLegacyArrayData(t *testing.T) {
+	SetLegacyArrayData(true)
+	t.Cleanup(func() { SetLegacyArrayData(false) })
+
+	recorder := httptest.NewRecorder()
+	Success(recorder, user{Name: "a"})
+	if got := dataField(t, recorder); got != `[{"name":"a"}]` {
+		t.Fatalf("expected legacy array data, got %s", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	Success(recorder)
+	if got := dataField(t, recorder); got != `[]` {
+		t.Fatalf("expected legacy empty array, got %s", got)
+	}
+}
+
+func dataField(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+
+	return string(body.Data)
+}
@@ -0,0 +1,116 @@
+package response
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ServeOption customizes how a single download is presented to the client.
+type ServeOption func(*serveOptions)
+
+type serveOptions struct {
+	inline   bool
+	filename string
+}
+
+// Inline asks the browser to display the download instead of saving it as an attachment.
+func Inline() ServeOption {
+	return func(o *serveOptions) {
+		o.inline = true
+	}
+}
+
+// Filename overrides the file name presented to the client, independently of the stored name.
+func Filename(name string) ServeOption {
+	return func(o *serveOptions) {
+		o.filename = name
+	}
+}
+
+func buildServeOptions(opts []ServeOption) serveOptions {
+	var o serveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// presentedName returns the overriding file name if one was configured, otherwise name.
+func (o serveOptions) presentedName(name string) string {
+	if o.filename != "" {
+		return o.filename
+	}
+
+	return name
+}
+
+// contentDisposition formats a Content-Disposition value following RFC 6266. Names that are
+// not plain ASCII get an ASCII fallback in filename and the exact name in filename*.
+func (o serveOptions) contentDisposition(name string) string {
+	dispositionType := "attachment"
+	if o.inline {
+		dispositionType = "inline"
+	}
+
+	fallback := asciiFilename(name)
+	value := dispositionType + `; filename="` + fallback + `"`
+	if fallback != quoteEscape(name) {
+		value += "; filename*=UTF-8''" + encodeExtValue(name)
+	}
+
+	return value
+}
+
+// asciiFilename replaces characters that cannot appear in a quoted-string filename with
+// underscores and escapes quotes and backslashes.
+func asciiFilename(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f || r >= utf8.RuneSelf:
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// quoteEscape escapes quotes and backslashes for use in a quoted-string.
+func quoteEscape(name string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)
+}
+
+// encodeExtValue percent-encodes name as an RFC 8187 ext-value, leaving only attr-char unescaped.
+func encodeExtValue(name string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
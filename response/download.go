@@ -1,26 +1,45 @@
 package response
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/starme/go-zero/httpx/errors"
 )
 
 var (
-	downloadRoot   string
-	downloadRootMu sync.RWMutex
+	defaultDownloader   = newDownloader()
+	defaultDownloaderMu sync.RWMutex
 )
 
+// DefaultDownloader returns the Downloader used by the package-level download functions.
+func DefaultDownloader() *Downloader {
+	defaultDownloaderMu.RLock()
+	defer defaultDownloaderMu.RUnlock()
+	return defaultDownloader
+}
+
+// SetDefaultDownloader replaces the Downloader used by the package-level download functions.
+func SetDefaultDownloader(d *Downloader) {
+	if d == nil {
+		return
+	}
+
+	defaultDownloaderMu.Lock()
+	defaultDownloader = d
+	defaultDownloaderMu.Unlock()
+}
+
 // SetDownloadRoot configures the absolute root directory that download paths must not escape.
+// It keeps the policy of the default Downloader and only swaps its root.
 func SetDownloadRoot(root string) error {
 	if root == "" {
 		return fmt.Errorf("download root cannot be empty")
@@ -31,62 +50,107 @@ func SetDownloadRoot(root string) error {
 		return fmt.Errorf("resolve download root %s: %w", root, err)
 	}
 
-	downloadRootMu.Lock()
-	downloadRoot = absRoot
-	downloadRootMu.Unlock()
+	defaultDownloaderMu.Lock()
+	defaultDownloader = defaultDownloader.withRoot(absRoot)
+	defaultDownloaderMu.Unlock()
 	return nil
 }
 
 func resetDownloadRoot() {
-	downloadRootMu.Lock()
-	downloadRoot = ""
-	downloadRootMu.Unlock()
+	defaultDownloaderMu.Lock()
+	defaultDownloader = newDownloader()
+	defaultDownloaderMu.Unlock()
 }
 
 // Download streams the file at path to the client, reporting failures via HttpError.
-func Download(w http.ResponseWriter, path string, err errors.HttpError) {
-	DownloadCtx(context.Background(), w, path, err)
+func Download(w http.ResponseWriter, path string, err errors.HttpError, opts ...ServeOption) {
+	DefaultDownloader().Download(w, path, err, opts...)
 }
 
 // DownloadCtx streams a file using the supplied context for tracing and error reporting.
-func DownloadCtx(ctx context.Context, w http.ResponseWriter, path string, err error) {
-	if err != nil {
-		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
-		return
-	}
+// Without access to the request it always sends the whole file; use ServeDownload to honor
+// Range and conditional request headers.
+func DownloadCtx(ctx context.Context, w http.ResponseWriter, path string, err error, opts ...ServeOption) {
+	DefaultDownloader().DownloadCtx(ctx, w, path, err, opts...)
+}
 
-	resolved, resolveErr := resolveDownloadPath(path)
-	if resolveErr != nil {
-		ErrorCtx(ctx, w, wrapDownloadErr(path, resolveErr))
-		return
+// ServeDownload streams the file at path like DownloadCtx while honoring the Range, If-Range,
+// If-Modified-Since and If-None-Match headers of r, answering 206, 304 or 416 where appropriate.
+func ServeDownload(w http.ResponseWriter, r *http.Request, path string, err error, opts ...ServeOption) {
+	DefaultDownloader().ServeDownload(w, r, path, err, opts...)
+}
+
+// ServeFS streams the named file from fsys, such as an embed.FS, with the same headers,
+// error reporting and range support as ServeDownload.
+func ServeFS(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, opts ...ServeOption) {
+	DefaultDownloader().ServeFS(w, r, fsys, name, opts...)
+}
+
+// ServeContent streams content presented to the client as name. A zero modtime omits the
+// Last-Modified header; range requests are served by seeking within content.
+func ServeContent(w http.ResponseWriter, r *http.Request, name string, modtime time.Time, content io.ReadSeeker,
+	opts ...ServeOption) {
+	DefaultDownloader().ServeContent(w, r, name, modtime, content, opts...)
+}
+
+// ServeBytes streams an in-memory payload such as a generated export, deriving its ETag from the data.
+func ServeBytes(w http.ResponseWriter, r *http.Request, name string, modtime time.Time, data []byte,
+	opts ...ServeOption) {
+	DefaultDownloader().ServeBytes(w, r, name, modtime, data, opts...)
+}
+
+// setDownloadHeaders writes the headers shared by every download source.
+func setDownloadHeaders(w http.ResponseWriter, name, contentType string, opts serveOptions) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", opts.contentDisposition(opts.presentedName(name)))
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
+}
+
+// seekableContent adapts an fs.File for range requests, falling back to buffering files
+// that implement neither io.Seeker nor io.ReaderAt.
+func seekableContent(file fs.File, size int64) (io.ReadSeeker, error) {
+	if rs, ok := file.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	if ra, ok := file.(io.ReaderAt); ok {
+		return io.NewSectionReader(ra, 0, size), nil
 	}
 
-	stat, err := os.Stat(resolved)
+	data, err := io.ReadAll(file)
 	if err != nil {
-		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
-		return
-	}
-	if !stat.Mode().IsRegular() {
-		ErrorCtx(ctx, w, wrapDownloadErr(path, fmt.Errorf("not a regular file")))
-		return
+		return nil, err
 	}
 
-	file, err := os.Open(resolved)
+	return bytes.NewReader(data), nil
+}
+
+// contentSize reports the size of content and rewinds it to the start.
+func contentSize(content io.Seeker) (int64, error) {
+	size, err := content.Seek(0, io.SeekEnd)
 	if err != nil {
-		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
-		return
+		return 0, fmt.Errorf("seek content: %w", err)
+	}
+	if _, err = content.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek content: %w", err)
 	}
-	defer file.Close()
 
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", url.QueryEscape(stat.Name())))
-	w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
-	w.Header().Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
+	return size, nil
+}
 
-	if _, err = io.Copy(w, file); err != nil {
-		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
-		return
+// serveContent writes content through http.ServeContent, which takes care of Content-Length,
+// Last-Modified, byte ranges and conditional requests. A nil request is served as a plain GET.
+func serveContent(ctx context.Context, w http.ResponseWriter, r *http.Request, name string,
+	modtime time.Time, content io.ReadSeeker) {
+	if r == nil {
+		r = (&http.Request{Method: http.MethodGet, Header: http.Header{}}).WithContext(ctx)
 	}
+
+	http.ServeContent(w, r, name, modtime, content)
+}
+
+// fileETag derives a strong validator from the modification time and size reported by os.Stat.
+func fileETag(stat os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size())
 }
 
 // wrapDownloadErr converts a filesystem error into a DownloadError with contextualized messaging.
@@ -97,37 +161,5 @@ func wrapDownloadErr(path string, err error) errors.HttpError {
 
 // resolveDownloadPath ensures the requested path resolves inside the configured download root.
 func resolveDownloadPath(path string) (string, error) {
-	downloadRootMu.RLock()
-	root := downloadRoot
-	downloadRootMu.RUnlock()
-	if root == "" {
-		return "", fmt.Errorf("download root is not configured")
-	}
-
-	cleaned := filepath.Clean(path)
-	if cleaned == "." {
-		return "", fmt.Errorf("download path cannot be empty")
-	}
-
-	var joined string
-	if filepath.IsAbs(cleaned) {
-		joined = cleaned
-	} else {
-		joined = filepath.Join(root, cleaned)
-	}
-
-	absPath, err := filepath.Abs(joined)
-	if err != nil {
-		return "", fmt.Errorf("resolve absolute path: %w", err)
-	}
-
-	rel, err := filepath.Rel(root, absPath)
-	if err != nil {
-		return "", fmt.Errorf("relate path to root: %w", err)
-	}
-	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("path escapes download root")
-	}
-
-	return absPath, nil
+	return DefaultDownloader().resolve(path)
 }
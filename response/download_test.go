@@ -3,13 +3,18 @@ package response
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/starme/go-zero/httpx/errors"
 )
 
 func TestResolveDownloadPathRootNotConfigured(t *testing.T) {
@@ -76,7 +81,7 @@ func TestDownloadCtxWritesFile(t *testing.T) {
 	if recorder.Result().StatusCode != http.StatusOK {
 		t.Fatalf("unexpected status: %d", recorder.Result().StatusCode)
 	}
-	expectedDisposition := fmt.Sprintf("attachment; filename=%s", url.QueryEscape(filename))
+	expectedDisposition := fmt.Sprintf("attachment; filename=%q", filename)
 	if got := recorder.Header().Get("Content-Disposition"); got != expectedDisposition {
 		t.Fatalf("unexpected Content-Disposition: %s", got)
 	}
@@ -98,3 +103,509 @@ func TestDownloadCtxWithoutRootFails(t *testing.T) {
 		t.Fatalf("expected bad request when root is missing, got %d", recorder.Result().StatusCode)
 	}
 }
+
+func TestServeDownloadSingleRange(t *testing.T) {
+	root := setupDownloadFile(t, "video.bin", []byte("0123456789"))
+
+	req := httptest.NewRequest(http.MethodGet, "/video.bin", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	recorder := httptest.NewRecorder()
+	ServeDownload(recorder, req, filepath.Join(root, "video.bin"), nil)
+
+	if recorder.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Fatalf("unexpected Content-Range: %s", got)
+	}
+	if got := recorder.Body.String(); got != "2345" {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestServeDownloadMultiRange(t *testing.T) {
+	setupDownloadFile(t, "video.bin", []byte("0123456789"))
+
+	req := httptest.NewRequest(http.MethodGet, "/video.bin", nil)
+	req.Header.Set("Range", "bytes=0-1,8-9")
+	recorder := httptest.NewRecorder()
+	ServeDownload(recorder, req, "video.bin", nil)
+
+	if recorder.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "multipart/byteranges; boundary=") {
+		t.Fatalf("unexpected Content-Type: %s", got)
+	}
+	body := recorder.Body.String()
+	if !strings.Contains(body, "bytes 0-1/10") || !strings.Contains(body, "bytes 8-9/10") {
+		t.Fatalf("missing byte range parts: %s", body)
+	}
+}
+
+func TestServeDownloadUnsatisfiableRange(t *testing.T) {
+	setupDownloadFile(t, "video.bin", []byte("0123456789"))
+
+	req := httptest.NewRequest(http.MethodGet, "/video.bin", nil)
+	req.Header.Set("Range", "bytes=20-30")
+	recorder := httptest.NewRecorder()
+	ServeDownload(recorder, req, "video.bin", nil)
+
+	if recorder.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("expected 416, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Range"); got != "bytes */10" {
+		t.Fatalf("unexpected Content-Range: %s", got)
+	}
+}
+
+func TestServeDownloadIfRangeMismatchSendsFullFile(t *testing.T) {
+	setupDownloadFile(t, "video.bin", []byte("0123456789"))
+
+	req := httptest.NewRequest(http.MethodGet, "/video.bin", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	req.Header.Set("If-Range", `"stale"`)
+	recorder := httptest.NewRecorder()
+	ServeDownload(recorder, req, "video.bin", nil)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if got := recorder.Body.String(); got != "0123456789" {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestServeDownloadConditionalHeaders(t *testing.T) {
+	setupDownloadFile(t, "report.txt", []byte("report"))
+
+	first := httptest.NewRecorder()
+	ServeDownload(first, httptest.NewRequest(http.MethodGet, "/report.txt", nil), "report.txt", nil)
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("expected validators, got ETag=%q Last-Modified=%q", etag, lastModified)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/report.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	recorder := httptest.NewRecorder()
+	ServeDownload(recorder, req, "report.txt", nil)
+	if recorder.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", recorder.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/report.txt", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	recorder = httptest.NewRecorder()
+	ServeDownload(recorder, req, "report.txt", nil)
+	if recorder.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for unmodified file, got %d", recorder.Code)
+	}
+}
+
+func setupDownloadFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	root := t.TempDir()
+	if err := SetDownloadRoot(root); err != nil {
+		t.Fatalf("failed to set download root: %v", err)
+	}
+	t.Cleanup(resetDownloadRoot)
+
+	if err := os.WriteFile(filepath.Join(root, name), content, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	return root
+}
+
+func TestServeFSWritesFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/logo.txt": {Data: []byte("logo"), ModTime: time.Unix(1700000000, 0)},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/logo.txt", nil)
+	req.Header.Set("Range", "bytes=1-2")
+	recorder := httptest.NewRecorder()
+	ServeFS(recorder, req, fsys, "assets/logo.txt")
+
+	if recorder.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", recorder.Code)
+	}
+	if got := recorder.Body.String(); got != "og" {
+		t.Fatalf("unexpected body: %s", got)
+	}
+	if got := recorder.Header().Get("Content-Disposition"); got != `attachment; filename="logo.txt"` {
+		t.Fatalf("unexpected Content-Disposition: %s", got)
+	}
+}
+
+func TestServeFSMissingFile(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ServeFS(recorder, httptest.NewRequest(http.MethodGet, "/", nil), fstest.MapFS{}, "missing.txt")
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected not found for missing file, got %d", recorder.Code)
+	}
+}
+
+func TestServeContentFromReadSeeker(t *testing.T) {
+	modtime := time.Unix(1700000000, 0)
+	recorder := httptest.NewRecorder()
+	ServeContent(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "export.csv", modtime,
+		strings.NewReader("a,b\n1,2\n"))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Last-Modified"); got != modtime.UTC().Format(http.TimeFormat) {
+		t.Fatalf("unexpected Last-Modified: %s", got)
+	}
+	if got := recorder.Body.String(); got != "a,b\n1,2\n" {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestServeBytesHonorsETag(t *testing.T) {
+	data := []byte("generated export")
+
+	first := httptest.NewRecorder()
+	ServeBytes(first, httptest.NewRequest(http.MethodGet, "/", nil), "export.txt", time.Time{}, data)
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag header")
+	}
+	if !bytes.Equal(first.Body.Bytes(), data) {
+		t.Fatalf("unexpected body: %s", first.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	recorder := httptest.NewRecorder()
+	ServeBytes(recorder, req, "export.txt", time.Time{}, data)
+	if recorder.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", recorder.Code)
+	}
+}
+
+func TestDownloaderInstancesUseOwnRoots(t *testing.T) {
+	reportsRoot, invoicesRoot := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(reportsRoot, "q3.export"), []byte{0x00, 0x01}, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(invoicesRoot, "q3.export"), []byte{0x00, 0x02}, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	reports, err := NewDownloader(reportsRoot)
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+	invoices, err := NewDownloader(invoicesRoot, WithDefaultContentType("application/x-invoice"))
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	reports.DownloadCtx(context.Background(), recorder, "q3.export", nil)
+	if got := recorder.Body.Bytes(); !bytes.Equal(got, []byte{0x00, 0x01}) {
+		t.Fatalf("unexpected body: %v", got)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/octet-stream" {
+		t.Fatalf("unexpected Content-Type: %s", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	invoices.DownloadCtx(context.Background(), recorder, "q3.export", nil)
+	if got := recorder.Body.Bytes(); !bytes.Equal(got, []byte{0x00, 0x02}) {
+		t.Fatalf("unexpected body: %v", got)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/x-invoice" {
+		t.Fatalf("unexpected Content-Type: %s", got)
+	}
+}
+
+func TestDownloaderRejectsDisallowedExtension(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "secret.key"), []byte("key"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	d, err := NewDownloader(root, WithAllowedExtensions("pdf", ".CSV"))
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	d.DownloadCtx(context.Background(), recorder, "secret.key", nil)
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected disallowed extension to be forbidden, got %d", recorder.Code)
+	}
+}
+
+func TestDownloaderRejectsOversizedContent(t *testing.T) {
+	d, err := NewDownloader(t.TempDir(), WithMaxSize(4))
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	d.ServeBytes(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "export.txt", time.Time{}, []byte("too large"))
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected oversized content to be forbidden, got %d", recorder.Code)
+	}
+}
+
+func TestSetDownloadRootKeepsDefaultPolicy(t *testing.T) {
+	d, err := NewDownloader(t.TempDir(), WithMaxSize(1))
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+	SetDefaultDownloader(d)
+	t.Cleanup(resetDownloadRoot)
+
+	root := t.TempDir()
+	if err = SetDownloadRoot(root); err != nil {
+		t.Fatalf("failed to set download root: %v", err)
+	}
+	if got := DefaultDownloader().Root(); got != root {
+		t.Fatalf("expected root %s, got %s", root, got)
+	}
+	if got := DefaultDownloader().maxSize; got != 1 {
+		t.Fatalf("expected max size to be kept, got %d", got)
+	}
+}
+
+func TestDownloaderSymlinkPolicies(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "passwd"), []byte("outside"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "report.txt"), []byte("inside"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "etc")); err != nil {
+		t.Fatalf("create symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "report.txt"), filepath.Join(root, "latest.txt")); err != nil {
+		t.Fatalf("create symlink: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		policy SymlinkPolicy
+		path   string
+		status int
+	}{
+		{name: "within root blocks escape", policy: SymlinkWithinRoot, path: "etc/passwd", status: http.StatusForbidden},
+		{name: "within root follows inner link", policy: SymlinkWithinRoot, path: "latest.txt", status: http.StatusOK},
+		{name: "deny blocks escape", policy: SymlinkDeny, path: "etc/passwd", status: http.StatusForbidden},
+		{name: "deny blocks inner link", policy: SymlinkDeny, path: "latest.txt", status: http.StatusForbidden},
+		{name: "deny serves plain file", policy: SymlinkDeny, path: "report.txt", status: http.StatusOK},
+		{name: "allow follows escape", policy: SymlinkAllow, path: "etc/passwd", status: http.StatusOK},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDownloader(root, WithSymlinkPolicy(tt.policy))
+			if err != nil {
+				t.Fatalf("create downloader: %v", err)
+			}
+
+			recorder := httptest.NewRecorder()
+			d.DownloadCtx(context.Background(), recorder, tt.path, nil)
+			if recorder.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestDownloaderRejectsSymlinkSwappedAfterResolve(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	target := filepath.Join(root, "report.txt")
+	if err := os.WriteFile(target, []byte("inside"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	for _, policy := range []SymlinkPolicy{SymlinkWithinRoot, SymlinkDeny} {
+		d, err := NewDownloader(root, WithSymlinkPolicy(policy))
+		if err != nil {
+			t.Fatalf("create downloader: %v", err)
+		}
+
+		// Replace the checked file with an escaping symlink right before the os.Root opens it.
+		d.beforeOpen = func(rel string) {
+			if rel != "report.txt" {
+				t.Fatalf("unexpected relative path %q", rel)
+			}
+			if err := os.Remove(target); err != nil {
+				t.Fatalf("remove file: %v", err)
+			}
+			if err := os.Symlink(filepath.Join(outside, "secret.txt"), target); err != nil {
+				t.Fatalf("create symlink: %v", err)
+			}
+		}
+		file, err := d.open("report.txt")
+		if err == nil {
+			file.Close()
+			t.Fatalf("policy %v: expected swapped symlink to be rejected", policy)
+		}
+
+		if err = os.Remove(target); err != nil {
+			t.Fatalf("remove symlink: %v", err)
+		}
+		if err = os.WriteFile(target, []byte("inside"), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+}
+
+func TestDownloadCtxContentDispositionOptions(t *testing.T) {
+	setupDownloadFile(t, "0b7f3c1e.pdf", []byte("%PDF"))
+
+	cases := []struct {
+		name     string
+		opts     []ServeOption
+		expected string
+	}{
+		{
+			name:     "stored name",
+			expected: `attachment; filename="0b7f3c1e.pdf"`,
+		},
+		{
+			name:     "presented name with spaces",
+			opts:     []ServeOption{Filename("report 2026-10.pdf")},
+			expected: `attachment; filename="report 2026-10.pdf"`,
+		},
+		{
+			name:     "inline",
+			opts:     []ServeOption{Inline(), Filename("report.pdf")},
+			expected: `inline; filename="report.pdf"`,
+		},
+		{
+			name:     "non-ascii name",
+			opts:     []ServeOption{Filename("报告 10月.pdf")},
+			expected: `attachment; filename="__ 10_.pdf"; filename*=UTF-8''%E6%8A%A5%E5%91%8A%2010%E6%9C%88.pdf`,
+		},
+		{
+			name:     "quoted name",
+			opts:     []ServeOption{Filename(`a"b.pdf`)},
+			expected: `attachment; filename="a\"b.pdf"`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			DownloadCtx(context.Background(), recorder, "0b7f3c1e.pdf", nil, tt.opts...)
+
+			if got := recorder.Header().Get("Content-Disposition"); got != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDownloaderDetectsContentType(t *testing.T) {
+	pngHeader := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	cases := []struct {
+		name     string
+		file     string
+		content  []byte
+		opts     []DownloaderOption
+		expected string
+	}{
+		{name: "sniffed content", file: "avatar", content: pngHeader, expected: "image/png"},
+		{name: "sniffed over misleading extension", file: "avatar.txt", content: pngHeader, expected: "image/png"},
+		{name: "extension for generic content", file: "data.json", content: []byte(`{"a":1}`), expected: "application/json"},
+		{
+			name:     "override hook",
+			file:     "avatar",
+			content:  pngHeader,
+			opts:     []DownloaderOption{WithContentTypeFunc(func(string, []byte) string { return "image/x-custom" })},
+			expected: "image/x-custom",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, tt.file), tt.content, 0o644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+
+			d, err := NewDownloader(root, tt.opts...)
+			if err != nil {
+				t.Fatalf("create downloader: %v", err)
+			}
+
+			recorder := httptest.NewRecorder()
+			d.ServeDownload(recorder, httptest.NewRequest(http.MethodGet, "/", nil), tt.file, nil)
+			if got := recorder.Header().Get("Content-Type"); got != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, got)
+			}
+			if !bytes.Equal(recorder.Body.Bytes(), tt.content) {
+				t.Fatalf("unexpected body: %v", recorder.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestDownloaderAllowedContentTypes(t *testing.T) {
+	d, err := NewDownloader(t.TempDir(), WithAllowedContentTypes("image/*", "application/pdf"))
+	if err != nil {
+		t.Fatalf("create downloader: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	d.ServeBytes(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "doc.pdf", time.Time{}, []byte("%PDF-1.7\n"))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected pdf to be allowed, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	d.ServeBytes(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "page.pdf", time.Time{},
+		[]byte("<html><script>alert(1)</script></html>"))
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected html disguised as pdf to be forbidden, got %d", recorder.Code)
+	}
+}
+
+func TestDownloadCtxErrorStatus(t *testing.T) {
+	setupDownloadFile(t, "report.txt", []byte("report"))
+
+	cases := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "missing file", path: "missing.txt", status: http.StatusNotFound},
+		{name: "escaping path", path: "../report.txt", status: http.StatusForbidden},
+		{name: "directory", path: ".", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			DownloadCtx(context.Background(), recorder, tt.path, nil)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, recorder.Code)
+			}
+
+			var body Body
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != int(errors.CodeDownload) {
+				t.Fatalf("expected business code %d, got %d", errors.CodeDownload, body.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/starme/go-zero/httpx/errors"
+)
+
+const defaultDownloadContentType = "application/octet-stream"
+
+// SymlinkPolicy decides how a Downloader treats symbolic links on the way to a requested file.
+type SymlinkPolicy int
+
+const (
+	// SymlinkWithinRoot follows symlinks as long as their targets stay inside the download root.
+	SymlinkWithinRoot SymlinkPolicy = iota
+	// SymlinkDeny rejects any path that traverses a symlink below the download root.
+	SymlinkDeny
+	// SymlinkAllow follows every symlink and only checks the requested path lexically.
+	SymlinkAllow
+)
+
+// Downloader serves files from a single root directory and applies its own download policy.
+// A Downloader is immutable once built and safe for concurrent use.
+type Downloader struct {
+	root                string
+	allowedExtensions   map[string]struct{}
+	allowedContentTypes []string
+	maxSize             int64
+	symlinkPolicy       SymlinkPolicy
+	defaultContentType  string
+	contentTypeFn       ContentTypeFunc
+	// beforeOpen is only set by tests to swap files between the path checks and the open.
+	beforeOpen func(rel string)
+}
+
+// DownloaderOption customizes a Downloader created by NewDownloader.
+type DownloaderOption func(*Downloader)
+
+// WithAllowedExtensions restricts downloads to the given file extensions, e.g. ".pdf" or "csv".
+func WithAllowedExtensions(exts ...string) DownloaderOption {
+	return func(d *Downloader) {
+		if d.allowedExtensions == nil {
+			d.allowedExtensions = make(map[string]struct{}, len(exts))
+		}
+
+		for _, ext := range exts {
+			ext = strings.ToLower(strings.TrimSpace(ext))
+			if ext == "" {
+				continue
+			}
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			d.allowedExtensions[ext] = struct{}{}
+		}
+	}
+}
+
+// WithMaxSize rejects downloads larger than size bytes. Zero or a negative size disables the limit.
+func WithMaxSize(size int64) DownloaderOption {
+	return func(d *Downloader) {
+		d.maxSize = size
+	}
+}
+
+// WithSymlinkPolicy sets how symlinks below the download root are handled.
+// The default is SymlinkWithinRoot.
+func WithSymlinkPolicy(policy SymlinkPolicy) DownloaderOption {
+	return func(d *Downloader) {
+		d.symlinkPolicy = policy
+	}
+}
+
+// WithDefaultContentType sets the Content-Type sent when detection cannot identify the content.
+func WithDefaultContentType(contentType string) DownloaderOption {
+	return func(d *Downloader) {
+		if contentType != "" {
+			d.defaultContentType = contentType
+		}
+	}
+}
+
+// NewDownloader creates a Downloader whose file paths must not escape root.
+func NewDownloader(root string, opts ...DownloaderOption) (*Downloader, error) {
+	if root == "" {
+		return nil, fmt.Errorf("download root cannot be empty")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve download root %s: %w", root, err)
+	}
+
+	d := newDownloader(opts...)
+	d.root = absRoot
+	return d, nil
+}
+
+func newDownloader(opts ...DownloaderOption) *Downloader {
+	d := &Downloader{defaultContentType: defaultDownloadContentType}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Root returns the absolute directory that download paths are resolved against.
+func (d *Downloader) Root() string {
+	return d.root
+}
+
+// withRoot returns a copy of d that resolves paths against root.
+func (d *Downloader) withRoot(root string) *Downloader {
+	clone := *d
+	clone.root = root
+	return &clone
+}
+
+// Download streams the file at path to the client, reporting failures via HttpError.
+func (d *Downloader) Download(w http.ResponseWriter, path string, err errors.HttpError, opts ...ServeOption) {
+	d.DownloadCtx(context.Background(), w, path, err, opts...)
+}
+
+// DownloadCtx streams a file using the supplied context for tracing and error reporting.
+// Without access to the request it always sends the whole file; use ServeDownload to honor
+// Range and conditional request headers.
+func (d *Downloader) DownloadCtx(ctx context.Context, w http.ResponseWriter, path string, err error,
+	opts ...ServeOption) {
+	d.serveDownload(ctx, w, nil, path, err, opts)
+}
+
+// ServeDownload streams the file at path like DownloadCtx while honoring the Range, If-Range,
+// If-Modified-Since and If-None-Match headers of r, answering 206, 304 or 416 where appropriate.
+func (d *Downloader) ServeDownload(w http.ResponseWriter, r *http.Request, path string, err error,
+	opts ...ServeOption) {
+	d.serveDownload(r.Context(), w, r, path, err, opts)
+}
+
+func (d *Downloader) serveDownload(ctx context.Context, w http.ResponseWriter, r *http.Request, path string, err error,
+	opts []ServeOption) {
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
+		return
+	}
+
+	file, err := d.open(path)
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
+		return
+	}
+	defer file.Close()
+
+	// Stat the opened handle rather than the path so the metadata always describes
+	// the file being streamed, even if the path is swapped in the meantime.
+	stat, err := file.Stat()
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
+		return
+	}
+	if !stat.Mode().IsRegular() {
+		ErrorCtx(ctx, w, wrapDownloadErr(path, fmt.Errorf("not a regular file")))
+		return
+	}
+	if err = d.check(stat.Name(), stat.Size()); err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
+		return
+	}
+
+	contentType, err := d.detectContentType(stat.Name(), file)
+	if err == nil {
+		err = d.checkContentType(contentType)
+	}
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(path, err))
+		return
+	}
+
+	d.setHeaders(w, stat.Name(), contentType, opts)
+	w.Header().Set("ETag", fileETag(stat))
+
+	serveContent(ctx, w, r, stat.Name(), stat.ModTime(), file)
+}
+
+// ServeFS streams the named file from fsys, such as an embed.FS, with the same headers,
+// error reporting and range support as ServeDownload.
+func (d *Downloader) ServeFS(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, opts ...ServeOption) {
+	ctx := r.Context()
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, err))
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, err))
+		return
+	}
+	if !stat.Mode().IsRegular() {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, fmt.Errorf("not a regular file")))
+		return
+	}
+	if err = d.check(stat.Name(), stat.Size()); err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, err))
+		return
+	}
+
+	content, err := seekableContent(file, stat.Size())
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, err))
+		return
+	}
+
+	contentType, err := d.detectContentType(stat.Name(), content)
+	if err == nil {
+		err = d.checkContentType(contentType)
+	}
+	if err != nil {
+		ErrorCtx(ctx, w, wrapDownloadErr(name, err))
+		return
+	}
+
+	d.setHeaders(w, stat.Name(), contentType, opts)
+	if !stat.ModTime().IsZero() {
+		w.Header().Set("ETag", fileETag(stat))
+	}
+
+	serveContent(ctx, w, r, stat.Name(), stat.ModTime(), content)
+}
+
+// ServeContent streams content presented to the client as name. A zero modtime omits the
+// Last-Modified header; range requests are served by seeking within content.
+func (d *Downloader) ServeContent(w http.ResponseWriter, r *http.Request, name string, modtime time.Time,
+	content io.ReadSeeker, opts ...ServeOption) {
+	if content == nil {
+		ErrorCtx(r.Context(), w, wrapDownloadErr(name, fmt.Errorf("content cannot be nil")))
+		return
+	}
+
+	size, err := contentSize(content)
+	if err != nil {
+		ErrorCtx(r.Context(), w, wrapDownloadErr(name, err))
+		return
+	}
+	if err = d.check(name, size); err != nil {
+		ErrorCtx(r.Context(), w, wrapDownloadErr(name, err))
+		return
+	}
+
+	contentType, err := d.detectContentType(name, content)
+	if err == nil {
+		err = d.checkContentType(contentType)
+	}
+	if err != nil {
+		ErrorCtx(r.Context(), w, wrapDownloadErr(name, err))
+		return
+	}
+
+	d.setHeaders(w, name, contentType, opts)
+	serveContent(r.Context(), w, r, name, modtime, content)
+}
+
+// ServeBytes streams an in-memory payload such as a generated export, deriving its ETag from the data.
+func (d *Downloader) ServeBytes(w http.ResponseWriter, r *http.Request, name string, modtime time.Time,
+	data []byte, opts ...ServeOption) {
+	if err := d.check(name, int64(len(data))); err != nil {
+		ErrorCtx(r.Context(), w, wrapDownloadErr(name, err))
+		return
+	}
+
+	contentType := d.contentType(name, data[:min(len(data), sniffLen)])
+	if err := d.checkContentType(contentType); err != nil {
+		ErrorCtx(r.Context(), w, wrapDownloadErr(name, err))
+		return
+	}
+
+	sum := sha256.Sum256(data)
+
+	d.setHeaders(w, name, contentType, opts)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, sum[:16]))
+
+	serveContent(r.Context(), w, r, name, modtime, bytes.NewReader(data))
+}
+
+// check enforces the extension allow-list and size limit of the Downloader.
+func (d *Downloader) check(name string, size int64) error {
+	if len(d.allowedExtensions) > 0 {
+		ext := strings.ToLower(filepath.Ext(name))
+		if _, ok := d.allowedExtensions[ext]; !ok {
+			return fmt.Errorf("file extension %q is not allowed: %w", ext, fs.ErrPermission)
+		}
+	}
+
+	if d.maxSize > 0 && size > d.maxSize {
+		return fmt.Errorf("file size %d exceeds limit of %d bytes: %w", size, d.maxSize, fs.ErrPermission)
+	}
+
+	return nil
+}
+
+// setHeaders writes the headers shared by every download source.
+func (d *Downloader) setHeaders(w http.ResponseWriter, name, contentType string, opts []ServeOption) {
+	setDownloadHeaders(w, name, contentType, buildServeOptions(opts))
+}
+
+// open resolves path inside the root and opens it according to the symlink policy.
+// Files are opened through an os.Root handle on a symlink-free path, so a symlink that
+// escapes the root is rejected at open time even if it was planted after resolution.
+func (d *Downloader) open(path string) (*os.File, error) {
+	resolved, err := d.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.symlinkPolicy == SymlinkAllow {
+		return os.Open(resolved)
+	}
+
+	rootDir, rel, err := d.rootRelative(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("open download root: %w", err)
+	}
+	defer root.Close()
+
+	if d.symlinkPolicy == SymlinkDeny {
+		if err = rejectSymlinks(root, rel); err != nil {
+			return nil, err
+		}
+	}
+	if d.beforeOpen != nil {
+		d.beforeOpen(rel)
+	}
+
+	return root.Open(rel)
+}
+
+// rootRelative splits resolved into a root directory and a path relative to it. Under
+// SymlinkWithinRoot both are symlink-evaluated and the target must remain inside the root.
+func (d *Downloader) rootRelative(resolved string) (string, string, error) {
+	if d.symlinkPolicy == SymlinkDeny {
+		rel, err := filepath.Rel(d.root, resolved)
+		if err != nil {
+			return "", "", fmt.Errorf("relate path to root: %w", err)
+		}
+
+		return d.root, rel, nil
+	}
+
+	realRoot, err := filepath.EvalSymlinks(d.root)
+	if err != nil {
+		return "", "", fmt.Errorf("evaluate download root: %w", err)
+	}
+	realPath, err := filepath.EvalSymlinks(resolved)
+	if err != nil {
+		return "", "", err
+	}
+
+	rel, err := filepath.Rel(realRoot, realPath)
+	if err != nil {
+		return "", "", fmt.Errorf("relate path to root: %w", err)
+	}
+	if escapesRoot(rel) {
+		return "", "", fmt.Errorf("symlink target escapes download root: %w", fs.ErrPermission)
+	}
+
+	return realRoot, rel, nil
+}
+
+// rejectSymlinks fails if any component of rel below root is a symlink.
+func rejectSymlinks(root *os.Root, rel string) error {
+	current := ""
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+
+		info, err := root.Lstat(current)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("path traverses symlink %s: %w", current, fs.ErrPermission)
+		}
+	}
+
+	return nil
+}
+
+// resolve ensures the requested path lexically resolves inside the root of the Downloader.
+// Symlinks are evaluated later by open.
+func (d *Downloader) resolve(path string) (string, error) {
+	root := d.root
+	if root == "" {
+		return "", fmt.Errorf("download root is not configured")
+	}
+
+	cleaned := filepath.Clean(path)
+	if cleaned == "." {
+		return "", fmt.Errorf("download path cannot be empty")
+	}
+
+	var joined string
+	if filepath.IsAbs(cleaned) {
+		joined = cleaned
+	} else {
+		joined = filepath.Join(root, cleaned)
+	}
+
+	absPath, err := filepath.Abs(joined)
+	if err != nil {
+		return "", fmt.Errorf("resolve absolute path: %w", err)
+	}
+
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return "", fmt.Errorf("relate path to root: %w", err)
+	}
+	if escapesRoot(rel) {
+		return "", fmt.Errorf("path escapes download root: %w", fs.ErrPermission)
+	}
+
+	return absPath, nil
+}
+
+// escapesRoot reports whether a path relative to the root points outside of it.
+func escapesRoot(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
@@ -0,0 +1,115 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/starme/go-zero/httpx/errors"
+)
+
+// Envelope builds the JSON bodies written by Success, Error and Response. data has already been
+// resolved: the values passed to Success, the data of an errors.DataError, or the configured
+// empty representation.
+type Envelope interface {
+	// Success builds the body of a successful response.
+	Success(ctx context.Context, code int, data any) any
+	// Error builds the body of an error response.
+	Error(ctx context.Context, err errors.HttpError, data any) any
+}
+
+// DefaultEnvelope writes the Body{Code, Msg, Data} shape, with "success" as the message of
+// successful responses.
+type DefaultEnvelope struct{}
+
+// Success returns Body{Code: code, Msg: "success", Data: data}.
+func (DefaultEnvelope) Success(_ context.Context, code int, data any) any {
+	return Body{Code: code, Msg: "success", Data: data}
+}
+
+// Error returns a Body with the business code and message of err.
+func (DefaultEnvelope) Error(_ context.Context, err errors.HttpError, data any) any {
+	return Body{Code: int(err.Code()), Msg: err.Error(), Data: data}
+}
+
+// KeyedEnvelope writes the code, message and data under custom keys, e.g.
+// {"errcode", "errmsg", "result"}, plus any fields returned by Extra.
+type KeyedEnvelope struct {
+	CodeKey string
+	MsgKey  string
+	DataKey string
+	// SuccessMsg is the message of successful responses.
+	SuccessMsg string
+	// Extra, when set, adds fields such as a trace id to every body. It cannot replace the
+	// code, message or data.
+	Extra func(ctx context.Context) map[string]any
+}
+
+// Success builds the body of a successful response.
+func (e KeyedEnvelope) Success(ctx context.Context, code int, data any) any {
+	return e.body(ctx, code, e.SuccessMsg, data)
+}
+
+// Error builds the body of an error response.
+func (e KeyedEnvelope) Error(ctx context.Context, err errors.HttpError, data any) any {
+	return e.body(ctx, int(err.Code()), err.Error(), data)
+}
+
+func (e KeyedEnvelope) body(ctx context.Context, code int, msg string, data any) map[string]any {
+	body := map[string]any{}
+	if e.Extra != nil {
+		for k, v := range e.Extra(ctx) {
+			body[k] = v
+		}
+	}
+	body[e.CodeKey] = code
+	body[e.MsgKey] = msg
+	body[e.DataKey] = data
+
+	return body
+}
+
+type envelopeKey struct{}
+
+var (
+	envelope   Envelope = DefaultEnvelope{}
+	envelopeMu sync.RWMutex
+)
+
+// SetEnvelope replaces the envelope used when the context does not choose one.
+func SetEnvelope(e Envelope) {
+	if e == nil {
+		return
+	}
+
+	envelopeMu.Lock()
+	envelope = e
+	envelopeMu.Unlock()
+}
+
+// WithEnvelope returns a context that makes the ...Ctx functions write bodies with e.
+func WithEnvelope(ctx context.Context, e Envelope) context.Context {
+	return context.WithValue(ctx, envelopeKey{}, e)
+}
+
+// EnvelopeMiddleware makes the handlers of a server write bodies with e, e.g.
+// server.Use(response.EnvelopeMiddleware(e)). Handlers must pass r.Context() to the ...Ctx
+// functions; the functions without a context always use the global envelope.
+func EnvelopeMiddleware(e Envelope) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, r.WithContext(WithEnvelope(r.Context(), e)))
+		}
+	}
+}
+
+// envelopeFrom returns the envelope chosen by ctx or the global one.
+func envelopeFrom(ctx context.Context) Envelope {
+	if e, ok := ctx.Value(envelopeKey{}).(Envelope); ok && e != nil {
+		return e
+	}
+
+	envelopeMu.RLock()
+	defer envelopeMu.RUnlock()
+	return envelope
+}
@@ -0,0 +1,75 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+)
+
+func TestDefaultEnvelopeKeepsBody(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Success(recorder, map[string]int{"id": 1})
+	if got := strings.TrimSpace(recorder.Body.String()); got != `{"code":0,"msg":"success","data":{"id":1}}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	Error(recorder, errors.New(errors.CodeNotFound, ""))
+	if got := strings.TrimSpace(recorder.Body.String()); got != `{"code":104,"msg":"resource not found","data":null}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestSetEnvelope(t *testing.T) {
+	SetEnvelope(KeyedEnvelope{CodeKey: "errcode", MsgKey: "errmsg", DataKey: "result", SuccessMsg: "ok"})
+	t.Cleanup(func() { SetEnvelope(DefaultEnvelope{}) })
+
+	recorder := httptest.NewRecorder()
+	Success(recorder, 1)
+	if got := strings.TrimSpace(recorder.Body.String()); got != `{"errcode":0,"errmsg":"ok","result":1}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+
+	var ve validation.ValidateError
+	ve = ve.AddViolation(validation.FieldViolation{Name: "name", Rule: "required", Message: "name is required"})
+	recorder = httptest.NewRecorder()
+	Error(recorder, ve)
+	want := `{"errcode":100,"errmsg":"name is required","result":[{"field":"","name":"name","rule":"required","message":"name is required"}]}`
+	if got := strings.TrimSpace(recorder.Body.String()); got != want {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestEnvelopeMiddleware(t *testing.T) {
+	type traceKey struct{}
+	e := KeyedEnvelope{
+		CodeKey: "code", MsgKey: "message", DataKey: "data", SuccessMsg: "ok",
+		Extra: func(ctx context.Context) map[string]any {
+			return map[string]any{"trace_id": ctx.Value(traceKey{})}
+		},
+	}
+	handler := EnvelopeMiddleware(e)(func(w http.ResponseWriter, r *http.Request) {
+		SuccessCtx(r.Context(), w)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), traceKey{}, "abc"))
+	recorder := httptest.NewRecorder()
+	handler(recorder, r)
+
+	if got := strings.TrimSpace(recorder.Body.String()); got != `{"code":0,"data":null,"message":"ok","trace_id":"abc"}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+
+	// Responses outside the middleware keep the global envelope.
+	recorder = httptest.NewRecorder()
+	Success(recorder)
+	if got := strings.TrimSpace(recorder.Body.String()); got != `{"code":0,"msg":"success","data":null}` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
@@ -0,0 +1,97 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io/fs"
+	"sync"
+
+	"github.com/starme/go-zero/httpx/errors"
+)
+
+// ErrorMapper converts an error that carries no HttpError into one.
+// It returns nil for errors it does not recognize.
+type ErrorMapper func(err error) errors.HttpError
+
+var (
+	errorMappers   []ErrorMapper
+	errorMappersMu sync.RWMutex
+
+	defaultErrorMappers = []ErrorMapper{
+		mapErrorIs(context.Canceled, errors.CodeCanceled),
+		mapErrorIs(context.DeadlineExceeded, errors.CodeTimeout),
+		mapErrorIs(fs.ErrNotExist, errors.CodeNotFound),
+		mapErrorAs[*json.SyntaxError](errors.CodeInvalidRequest),
+		mapErrorAs[*json.UnmarshalTypeError](errors.CodeInvalidRequest),
+	}
+)
+
+// RegisterErrorMapper adds a mapper that is consulted before the built-in mappings.
+// Mappers registered later take precedence over earlier ones.
+func RegisterErrorMapper(mapper ErrorMapper) {
+	if mapper == nil {
+		return
+	}
+
+	errorMappersMu.Lock()
+	errorMappers = append([]ErrorMapper{mapper}, errorMappers...)
+	errorMappersMu.Unlock()
+}
+
+// MapError reports errors matching target under errors.Is with the given business code.
+// The HTTP status and message come from the catalog definition of code.
+func MapError(target error, code errors.HttpCode) {
+	RegisterErrorMapper(mapErrorIs(target, code))
+}
+
+// toHttpError finds the HttpError carried by err, then tries the registered and built-in
+// mappers, and finally reports err as errors.CodeUnknown.
+func toHttpError(err error) errors.HttpError {
+	if err == nil {
+		return nil
+	}
+
+	var httpErr errors.HttpError
+	if stderrors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	errorMappersMu.RLock()
+	mappers := errorMappers
+	errorMappersMu.RUnlock()
+
+	for _, mapper := range mappers {
+		if mapped := mapper(err); mapped != nil {
+			return mapped
+		}
+	}
+	for _, mapper := range defaultErrorMappers {
+		if mapped := mapper(err); mapped != nil {
+			return mapped
+		}
+	}
+
+	return errors.Wrap(err, errors.CodeUnknown)
+}
+
+func mapErrorIs(target error, code errors.HttpCode) ErrorMapper {
+	return func(err error) errors.HttpError {
+		if stderrors.Is(err, target) {
+			return errors.Wrap(err, code)
+		}
+
+		return nil
+	}
+}
+
+func mapErrorAs[T error](code errors.HttpCode) ErrorMapper {
+	return func(err error) errors.HttpError {
+		var target T
+		if stderrors.As(err, &target) {
+			return errors.Wrap(err, code)
+		}
+
+		return nil
+	}
+}
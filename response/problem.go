@@ -0,0 +1,275 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details responses.
+const ProblemContentType = "application/problem+json"
+
+// ErrorFormat selects how ErrorCtx renders errors.
+type ErrorFormat int
+
+const (
+	// FormatEnvelope renders errors inside the Body{Code, Msg, Data} envelope.
+	FormatEnvelope ErrorFormat = iota
+	// FormatProblem renders errors as RFC 9457 problem details.
+	FormatProblem
+)
+
+// ProblemDetails is an RFC 9457 problem details object. Extensions are serialized as
+// additional top-level members next to the standard ones.
+type ProblemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// MarshalJSON flattens Extensions into the problem object and omits empty standard members.
+// Extensions cannot override the standard members.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+	setMember(members, "title", p.Title)
+	setMember(members, "detail", p.Detail)
+	setMember(members, "instance", p.Instance)
+	if p.Status != 0 {
+		members["status"] = p.Status
+	} else {
+		delete(members, "status")
+	}
+
+	return json.Marshal(members)
+}
+
+func setMember(members map[string]any, key, value string) {
+	if value == "" {
+		delete(members, key)
+		return
+	}
+
+	members[key] = value
+}
+
+type (
+	errorFormatKey     struct{}
+	problemInstanceKey struct{}
+)
+
+var (
+	errorFormat       = FormatEnvelope
+	problemTypeBase   string
+	errorFormatConfMu sync.RWMutex
+)
+
+// SetErrorFormat selects the error format used when the request context does not choose one.
+func SetErrorFormat(format ErrorFormat) {
+	errorFormatConfMu.Lock()
+	errorFormat = format
+	errorFormatConfMu.Unlock()
+}
+
+// SetProblemTypeBase makes problem types resolvable URIs: errors with a catalog key
+// get base + key as their type instead of "about:blank".
+func SetProblemTypeBase(base string) {
+	errorFormatConfMu.Lock()
+	problemTypeBase = base
+	errorFormatConfMu.Unlock()
+}
+
+// WithErrorFormat returns a context that makes ErrorCtx render errors in format.
+func WithErrorFormat(ctx context.Context, format ErrorFormat) context.Context {
+	return context.WithValue(ctx, errorFormatKey{}, format)
+}
+
+// ErrorFormatMiddleware selects FormatProblem for requests whose Accept header asks for
+// application/problem+json and records the request URI as the problem instance.
+func ErrorFormatMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), problemInstanceKey{}, r.URL.RequestURI())
+		if acceptsProblem(r.Header.Values("Accept")) {
+			ctx = WithErrorFormat(ctx, FormatProblem)
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// acceptsProblem reports whether the Accept header lists problem details with a non-zero quality
+// that is at least that of plain JSON, which application/* and */* match when not listed.
+func acceptsProblem(accept []string) bool {
+	qualities := make(map[string]float64)
+	for _, value := range accept {
+		for _, mediaRange := range strings.Split(value, ",") {
+			mediaType, params, _ := strings.Cut(mediaRange, ";")
+			mediaType = normalizeMediaType(mediaType)
+			q := acceptQuality(params)
+			if prev, ok := qualities[mediaType]; !ok || q > prev {
+				qualities[mediaType] = q
+			}
+		}
+	}
+
+	problem := qualities[ProblemContentType]
+	if problem <= 0 {
+		return false
+	}
+	for _, mediaType := range []string{"application/json", "application/*", "*/*"} {
+		if q, ok := qualities[mediaType]; ok {
+			return problem >= q
+		}
+	}
+
+	return true
+}
+
+// acceptQuality returns the q parameter of an Accept media range, defaulting to 1.
+func acceptQuality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+
+	return 1
+}
+
+// Problem writes err as RFC 9457 problem details regardless of the configured error format.
+func Problem(w http.ResponseWriter, err error) {
+	ProblemCtx(context.Background(), w, err)
+}
+
+// ProblemCtx writes err as RFC 9457 problem details while carrying the supplied context.
+func ProblemCtx(ctx context.Context, w http.ResponseWriter, err error) {
+	writeProblem(ctx, w, NewProblemDetails(ctx, toHttpError(err)))
+}
+
+// NewProblemDetails builds the problem details for err. Validation failures are listed in an
+// "errors" extension and the business code is exposed as the "code" extension.
+func NewProblemDetails(ctx context.Context, err errors.HttpError) ProblemDetails {
+	status := errorStatus(err)
+	problem := ProblemDetails{
+		Title:      http.StatusText(status),
+		Status:     status,
+		Extensions: map[string]any{},
+	}
+	if problem.Title == "" {
+		problem.Title = "Error"
+	}
+	if instance, ok := ctx.Value(problemInstanceKey{}).(string); ok {
+		problem.Instance = instance
+	}
+	if err == nil {
+		return problem
+	}
+
+	problem.Detail = err.Error()
+	problem.Extensions["code"] = err.Code()
+
+	errorFormatConfMu.RLock()
+	base := problemTypeBase
+	errorFormatConfMu.RUnlock()
+	if keyed, ok := err.(interface{ Key() string }); ok && base != "" && keyed.Key() != "" {
+		problem.Type = base + keyed.Key()
+	}
+
+	var validateErr validation.ValidateError
+	if stderrors.As(err, &validateErr) {
+		problem.Extensions["errors"] = problemViolations(validateErr.Violations())
+	}
+
+	return problem
+}
+
+// problemViolations renders field violations as members of the "errors" extension, locating
+// each field with a JSON pointer into the request body.
+func problemViolations(violations []validation.FieldViolation) []map[string]any {
+	members := make([]map[string]any, 0, len(violations))
+	for _, violation := range violations {
+		member := map[string]any{"detail": violation.Message}
+		if violation.Name != "" {
+			member["field"] = violation.Name
+			member["pointer"] = jsonPointer(violation.Name)
+		}
+		if violation.Rule != "" {
+			member["rule"] = violation.Rule
+		}
+		if violation.Param != "" {
+			member["param"] = violation.Param
+		}
+		members = append(members, member)
+	}
+
+	return members
+}
+
+// jsonPointer converts a field path such as "items[0].name" into "/items/0/name".
+func jsonPointer(path string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	var b strings.Builder
+	for _, segment := range strings.Split(path, ".") {
+		name, index, _ := strings.Cut(segment, "[")
+		b.WriteString("/" + escaper.Replace(name))
+		for _, idx := range strings.Split(strings.TrimSuffix(index, "]"), "][") {
+			if idx != "" {
+				b.WriteString("/" + escaper.Replace(idx))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// errorFormatFrom returns the error format chosen by ctx or the global default.
+func errorFormatFrom(ctx context.Context) ErrorFormat {
+	if format, ok := ctx.Value(errorFormatKey{}).(ErrorFormat); ok {
+		return format
+	}
+
+	errorFormatConfMu.RLock()
+	defer errorFormatConfMu.RUnlock()
+	return errorFormat
+}
+
+func writeProblem(ctx context.Context, w http.ResponseWriter, problem ProblemDetails) {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("marshal problem details: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	if _, err = w.Write(body); err != nil {
+		logx.WithContext(ctx).Errorf("write problem details: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
+)
+
+func TestProblemWritesProblemDetails(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Problem(recorder, errors.New(errors.CodeNotFound, "order 42 not found"))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != ProblemContentType {
+		t.Fatalf("unexpected Content-Type: %s", got)
+	}
+
+	var problem map[string]any
+	if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	expected := map[string]any{
+		"type":   "about:blank",
+		"title":  "Not Found",
+		"status": float64(http.StatusNotFound),
+		"detail": "order 42 not found",
+		"code":   float64(errors.CodeNotFound),
+	}
+	for key, want := range expected {
+		if problem[key] != want {
+			t.Fatalf("member %s: expected %v, got %v", key, want, problem[key])
+		}
+	}
+}
+
+func TestProblemRendersValidationErrors(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Problem(recorder, validation.ValidateError{}.AddString("name is required").AddString("age is invalid"))
+
+	var problem struct {
+		Status int `json:"status"`
+		Errors []struct {
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if problem.Status != http.StatusBadRequest || len(problem.Errors) != 2 {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+	if problem.Errors[0].Detail != "name is required" {
+		t.Fatalf("unexpected violation: %+v", problem.Errors[0])
+	}
+}
+
+func TestProblemRendersFieldViolations(t *testing.T) {
+	ve := validation.ValidateError{}.AddViolation(validation.FieldViolation{
+		Field: "Items[1].SKU", Name: "items[1].sku", Rule: "required", Message: "sku is required",
+	})
+
+	problem := NewProblemDetails(context.Background(), ve)
+	violations, ok := problem.Extensions["errors"].([]map[string]any)
+	if !ok || len(violations) != 1 {
+		t.Fatalf("unexpected errors extension: %v", problem.Extensions["errors"])
+	}
+
+	expected := map[string]any{
+		"detail":  "sku is required",
+		"field":   "items[1].sku",
+		"pointer": "/items/1/sku",
+		"rule":    "required",
+	}
+	for key, want := range expected {
+		if violations[0][key] != want {
+			t.Fatalf("member %s: expected %v, got %v", key, want, violations[0][key])
+		}
+	}
+}
+
+func TestProblemTypeBase(t *testing.T) {
+	SetProblemTypeBase("https://errors.example.com/")
+	t.Cleanup(func() { SetProblemTypeBase("") })
+
+	problem := NewProblemDetails(context.Background(), errors.New(errors.CodeTimeout, ""))
+	if problem.Type != "https://errors.example.com/timeout" {
+		t.Fatalf("unexpected type: %s", problem.Type)
+	}
+}
+
+func TestProblemDetailsExtensionsCannotOverrideMembers(t *testing.T) {
+	data, err := json.Marshal(ProblemDetails{Status: 400, Extensions: map[string]any{"status": "bogus", "trace": "abc"}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var problem map[string]any
+	if err = json.Unmarshal(data, &problem); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if problem["status"] != float64(400) || problem["trace"] != "abc" {
+		t.Fatalf("unexpected members: %v", problem)
+	}
+}
+
+func TestErrorFormatMiddlewareNegotiates(t *testing.T) {
+	handler := ErrorFormatMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		ErrorCtx(r.Context(), w, errors.New(errors.CodeNotFound, ""))
+	})
+
+	cases := []struct {
+		accept      string
+		contentType string
+	}{
+		{accept: "application/problem+json", contentType: ProblemContentType},
+		{accept: "application/json;q=0.5, application/problem+json", contentType: ProblemContentType},
+		{accept: "application/problem+json, application/json", contentType: ProblemContentType},
+		{accept: "application/json, application/problem+json;q=0.5", contentType: "application/json; charset=utf-8"},
+		{accept: "application/problem+json;q=0.8, */*", contentType: "application/json; charset=utf-8"},
+		{accept: "application/problem+json;q=0.8, application/*;q=0.5, */*", contentType: ProblemContentType},
+		{accept: "application/problem+json; q = 0.5, application/json; q = 0.9", contentType: "application/json; charset=utf-8"},
+		{accept: "application/json; q = 0.5, application/problem+json; q = 0.9", contentType: ProblemContentType},
+		{accept: "application/problem+json;q=0", contentType: "application/json; charset=utf-8"},
+		{accept: "application/json", contentType: "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders/42?x=1", nil)
+			req.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			handler(recorder, req)
+
+			if got := recorder.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("expected %s, got %s", tt.contentType, got)
+			}
+			if tt.contentType != ProblemContentType {
+				return
+			}
+
+			var problem map[string]any
+			if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if problem["instance"] != "/orders/42?x=1" {
+				t.Fatalf("unexpected instance: %v", problem["instance"])
+			}
+		})
+	}
+}
+
+func TestSetErrorFormatProblem(t *testing.T) {
+	SetErrorFormat(FormatProblem)
+	t.Cleanup(func() { SetErrorFormat(FormatEnvelope) })
+
+	recorder := httptest.NewRecorder()
+	Error(recorder, errors.New(errors.CodeNotFound, ""))
+	if got := recorder.Header().Get("Content-Type"); got != ProblemContentType {
+		t.Fatalf("unexpected Content-Type: %s", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	ErrorCtx(WithErrorFormat(context.Background(), FormatEnvelope), recorder, errors.New(errors.CodeNotFound, ""))
+	if got := recorder.Header().Get("Content-Type"); got == ProblemContentType {
+		t.Fatalf("expected context to override the global format")
+	}
+}
@@ -8,66 +8,81 @@ import (
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-// Body defines the standard envelope returned for HTTP responses.
+// Body defines the standard envelope returned for HTTP responses. It is the shape written by
+// DefaultEnvelope; see SetEnvelope for others.
 type Body struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
 	Data any    `json:"data"`
 }
 
-// Success writes a default HTTP 200 response with the provided payload.
+// Success writes a default HTTP 200 response with the provided payload. A single value becomes
+// the data as is, several values are written as an array, and no value (or a nil one) is
+// written as configured by SetEmptyData. Use SuccessList for lists, so that an empty list is
+// written as [].
 func Success(w http.ResponseWriter, data ...any) {
 	SuccessCtx(context.Background(), w, data...)
 }
 
 // SuccessCtx writes a success response using the provided context for tracing/logging.
 func SuccessCtx(ctx context.Context, w http.ResponseWriter, data ...any) {
-	responseCtx(ctx, w, http.StatusOK, 0, data, nil)
+	responseCtx(ctx, w, http.StatusOK, 0, successData(data), nil)
 }
 
-// Error writes an HttpError payload using the default context.
-func Error(w http.ResponseWriter, err errors.HttpError) {
+// Error writes an error payload using the default context.
+func Error(w http.ResponseWriter, err error) {
 	ErrorCtx(context.Background(), w, err)
 }
 
-// ErrorCtx writes an HttpError payload while carrying the supplied context.
-func ErrorCtx(ctx context.Context, w http.ResponseWriter, err errors.HttpError) {
-	responseCtx(ctx, w, http.StatusBadRequest, 0, nil, err)
+// ErrorCtx writes an error payload while carrying the supplied context. The HttpError in err's
+// chain is used when present; otherwise well-known errors are mapped (see RegisterErrorMapper)
+// and anything else is reported as errors.CodeUnknown. The HTTP status comes from
+// errors.StatusError when the resulting error implements it, and defaults to 400 otherwise.
+// Errors are written as problem details when the context or SetErrorFormat selects FormatProblem.
+func ErrorCtx(ctx context.Context, w http.ResponseWriter, err error) {
+	httpErr := toHttpError(err)
+	if errorFormatFrom(ctx) == FormatProblem {
+		writeProblem(ctx, w, NewProblemDetails(ctx, httpErr))
+		return
+	}
+
+	responseCtx(ctx, w, errorStatus(httpErr), 0, nil, httpErr)
 }
 
 // Response writes a response with explicit status, code, optional data, and error payloads.
-func Response(w http.ResponseWriter, status, code int, data any, err errors.HttpError) {
+func Response(w http.ResponseWriter, status, code int, data any, err error) {
 	ResponseCtx(context.Background(), w, status, code, data, err)
 }
 
 // ResponseCtx writes a response using the provided context.
-func ResponseCtx(ctx context.Context, w http.ResponseWriter, status, code int, data any, err errors.HttpError) {
-	responseCtx(ctx, w, status, code, data, err)
+func ResponseCtx(ctx context.Context, w http.ResponseWriter, status, code int, data any, err error) {
+	responseCtx(ctx, w, status, code, data, toHttpError(err))
 }
 
 func responseCtx(ctx context.Context, w http.ResponseWriter, status, code int, data any, err errors.HttpError) {
-	httpx.WriteJsonCtx(ctx, w, status, wrapResponse(code, data, err))
+	httpx.WriteJsonCtx(ctx, w, status, wrapResponse(ctx, code, data, err))
 }
 
-func wrapResponse(code int, data any, err errors.HttpError) Body {
-	body := Body{Code: code, Data: formatData(data), Msg: "success"}
-
-	if err != nil {
-		body.Code = int(err.Code())
-		body.Msg = err.Error()
+// errorStatus returns the HTTP status an error asks for, falling back to 400 Bad Request.
+func errorStatus(err errors.HttpError) int {
+	if se, ok := err.(errors.StatusError); ok && se.Status() != 0 {
+		return se.Status()
 	}
 
-	return body
+	return http.StatusBadRequest
 }
 
-func formatData(data any) any {
-	if data == nil {
-		return []any{}
+// wrapResponse builds the body with the envelope chosen by ctx, taking the data of an
+// errors.DataError when no data is given.
+func wrapResponse(ctx context.Context, code int, data any, err errors.HttpError) any {
+	e := envelopeFrom(ctx)
+	if err == nil {
+		return e.Success(ctx, code, formatData(data))
 	}
 
-	if slice, ok := data.([]any); ok && slice == nil {
-		return []any{}
+	if de, ok := err.(errors.DataError); ok && data == nil {
+		data = de.Data()
 	}
 
-	return data
+	return e.Error(ctx, err, formatData(data))
 }
@@ -1,11 +1,18 @@
 package response
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
+
+	"github.com/starme/go-zero/httpx/errors"
+	"github.com/starme/go-zero/httpx/validation"
 )
 
 func TestSuccessWritesEmptyData(t *testing.T) {
@@ -22,12 +29,8 @@ func TestSuccessWritesEmptyData(t *testing.T) {
 		t.Fatalf("decode body: %v", err)
 	}
 
-	dataSlice, ok := body.Data.([]any)
-	if !ok {
-		t.Fatalf("data should be []any, got %T", body.Data)
-	}
-	if len(dataSlice) != 0 {
-		t.Fatalf("expected empty data slice, got %v", dataSlice)
+	if body.Data != nil {
+		t.Fatalf("expected null data, got %v", body.Data)
 	}
 }
 
@@ -42,15 +45,8 @@ func TestSuccessWithData(t *testing.T) {
 		t.Fatalf("decode body: %v", err)
 	}
 
-	dataSlice, ok := body.Data.([]any)
-	if !ok {
-		t.Fatalf("data should be []any, got %T", body.Data)
-	}
-	if len(dataSlice) != 1 {
-		t.Fatalf("expected one element, got %v", dataSlice)
-	}
-	if !reflect.DeepEqual(dataSlice[0], payload) {
-		t.Fatalf("expected payload %v, got %v", payload, dataSlice[0])
+	if !reflect.DeepEqual(body.Data, payload) {
+		t.Fatalf("expected payload %v, got %v", payload, body.Data)
 	}
 }
 
@@ -86,3 +82,137 @@ func TestResponseUsesProvidedData(t *testing.T) {
 		t.Fatalf("unexpected code: %d", body.Code)
 	}
 }
+
+func TestErrorUsesStatusFromError(t *testing.T) {
+	// Built-in definitions keep the global catalog untouched by tests.
+	const code = errors.CodeNotFound
+
+	recorder := httptest.NewRecorder()
+	Error(recorder, errors.New(code, ""))
+
+	if recorder.Result().StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", recorder.Result().StatusCode)
+	}
+
+	var body Body
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != int(code) || body.Msg != "resource not found" {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+}
+
+func TestErrorDefaultsToBadRequest(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Error(recorder, codeOnlyError{})
+
+	if recorder.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", recorder.Result().StatusCode)
+	}
+}
+
+type codeOnlyError struct{}
+
+func (codeOnlyError) Error() string { return "code only" }
+
+func (codeOnlyError) Code() errors.HttpCode { return 7 }
+
+func TestErrorMapsPlainErrors(t *testing.T) {
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal([]byte("{"), &map[string]any{}); !stderrors.As(err, &syntaxErr) {
+		t.Fatalf("expected json syntax error, got %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   errors.HttpCode
+	}{
+		{name: "canceled", err: context.Canceled, status: errors.StatusClientClosedRequest, code: errors.CodeCanceled},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), status: http.StatusGatewayTimeout, code: errors.CodeTimeout},
+		{name: "not exist", err: os.ErrNotExist, status: http.StatusNotFound, code: errors.CodeNotFound},
+		{name: "json syntax", err: syntaxErr, status: http.StatusBadRequest, code: errors.CodeInvalidRequest},
+		{name: "wrapped http error", err: fmt.Errorf("login: %w", errors.New(errors.CodeValidation, "bad")), status: http.StatusBadRequest, code: errors.CodeValidation},
+		{name: "unknown", err: stderrors.New("db down"), status: http.StatusInternalServerError, code: errors.CodeUnknown},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			Error(recorder, tt.err)
+
+			if recorder.Result().StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, recorder.Result().StatusCode)
+			}
+
+			var body Body
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != int(tt.code) {
+				t.Fatalf("expected code %d, got %d", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func TestErrorHidesUnknownErrorMessage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Error(recorder, stderrors.New("dial tcp 10.0.0.1:5432: connection refused"))
+
+	var body Body
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Msg != "internal server error" {
+		t.Fatalf("expected generic message, got %s", body.Msg)
+	}
+}
+
+func TestMapErrorUsesCustomCode(t *testing.T) {
+	errQuota := stderrors.New("quota exceeded")
+	const code = errors.CodeUnsupportedMediaType
+	MapError(errQuota, code)
+	t.Cleanup(func() { errorMappers = nil })
+
+	recorder := httptest.NewRecorder()
+	Error(recorder, fmt.Errorf("export: %w", errQuota))
+
+	if recorder.Result().StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("unexpected status: %d", recorder.Result().StatusCode)
+	}
+
+	var body Body
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != int(code) {
+		t.Fatalf("unexpected code: %d", body.Code)
+	}
+}
+
+func TestErrorPutsViolationsIntoData(t *testing.T) {
+	ve := validation.ValidateError{}.AddViolation(validation.FieldViolation{
+		Field: "Account", Name: "account", Rule: "required", Message: "account is required",
+	})
+
+	recorder := httptest.NewRecorder()
+	Error(recorder, ve)
+
+	var body struct {
+		Code int                         `json:"code"`
+		Msg  string                      `json:"msg"`
+		Data []validation.FieldViolation `json:"data"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != int(errors.CodeValidation) || body.Msg != "account is required" {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if len(body.Data) != 1 || body.Data[0].Name != "account" || body.Data[0].Rule != "required" {
+		t.Fatalf("unexpected violations: %+v", body.Data)
+	}
+}
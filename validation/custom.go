@@ -0,0 +1,176 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// CustomValidator holds metadata for a custom validator and its translations.
+// Configure it with the chained setters and register it with Validator.AddCustom:
+//
+//	NewCustomValidator().
+//		Tag("mobile").
+//		Func(isMobile).
+//		Translation("en", "{0} must be a valid mobile number").
+//		Translation("zh", "{0}必须是有效的手机号码")
+type CustomValidator struct {
+	tag                  string
+	validateFunc         validator.Func
+	validateFuncCtx      validator.FuncCtx
+	translations         map[string]string
+	override             bool
+	customRegistrationFn validator.RegisterTranslationsFunc
+	customTranslateFn    validator.TranslationFunc
+}
+
+// NewCustomValidator creates an empty CustomValidator ready for chained configuration.
+func NewCustomValidator() *CustomValidator {
+	return &CustomValidator{translations: map[string]string{}}
+}
+
+// Tag sets the validation tag used in struct tags, e.g. "mobile" for `validate:"mobile"`.
+func (c *CustomValidator) Tag(tag string) *CustomValidator {
+	c.tag = tag
+	return c
+}
+
+// Func sets the validation function.
+func (c *CustomValidator) Func(fn validator.Func) *CustomValidator {
+	c.validateFunc = fn
+	return c
+}
+
+// FuncCtx sets a context-aware validation function. It takes precedence over Func.
+func (c *CustomValidator) FuncCtx(fn validator.FuncCtx) *CustomValidator {
+	c.validateFuncCtx = fn
+	return c
+}
+
+// Translation sets the message template for lang, a key of DefaultLocaleRegistry such as "zh".
+// Templates use {0} for the field name and {1} for the tag parameter.
+func (c *CustomValidator) Translation(lang, text string) *CustomValidator {
+	if c.translations == nil {
+		c.translations = map[string]string{}
+	}
+
+	c.translations[lang] = text
+	return c
+}
+
+// Override replaces existing translations registered for the same tag.
+func (c *CustomValidator) Override() *CustomValidator {
+	c.override = true
+	return c
+}
+
+// RegistrationFn replaces the per-language translations with a function that registers
+// translations on every translator of the Validator.
+func (c *CustomValidator) RegistrationFn(fn validator.RegisterTranslationsFunc) *CustomValidator {
+	c.customRegistrationFn = fn
+	return c
+}
+
+// TranslateFn sets how a validation failure is turned into a message.
+func (c *CustomValidator) TranslateFn(fn validator.TranslationFunc) *CustomValidator {
+	c.customTranslateFn = fn
+	return c
+}
+
+// AddCustom registers custom validation and translation rules on the validator. Every failure,
+// including a rejected validation function, is reported in the returned error; custom validators
+// that registered successfully stay usable.
+func (v *Validator) AddCustom(customValidator ...*CustomValidator) (*Validator, error) {
+	var errs []error
+	for _, c := range customValidator {
+		if err := v.registerCustomValidation(c); err != nil {
+			errs = append(errs, fmt.Errorf("register validation for tag %q failed: %w", c.tag, err))
+			continue
+		}
+
+		if err := v.registerCustomTranslations(c); err != nil {
+			errs = append(errs, fmt.Errorf("register translation for tag %q failed: %w", c.tag, err))
+			continue
+		}
+
+		v.customValidator = append(v.customValidator, c)
+	}
+
+	if len(errs) > 0 {
+		return v, errors.Join(errs...)
+	}
+
+	return v, nil
+}
+
+func (v *Validator) registerCustomValidation(c *CustomValidator) error {
+	switch {
+	case c.validateFuncCtx != nil:
+		return v.RegisterValidationCtx(c.tag, c.validateFuncCtx)
+	case c.validateFunc != nil:
+		return v.RegisterValidation(c.tag, c.validateFunc)
+	default:
+		return fmt.Errorf("no validation function configured")
+	}
+}
+
+func (v *Validator) registerCustomTranslations(c *CustomValidator) error {
+	translateFn := c.customTranslateFn
+	if translateFn == nil {
+		translateFn = validator.TranslationFunc(v.customTranslateFn)
+	}
+
+	if c.customRegistrationFn != nil {
+		for _, trans := range v.allTranslators() {
+			if err := v.RegisterTranslation(c.tag, trans, c.customRegistrationFn, translateFn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for lang, text := range c.translations {
+		targets := v.translatorsFor(lang)
+		if len(targets) == 0 {
+			return fmt.Errorf("no translator available for language %q", lang)
+		}
+
+		for _, trans := range targets {
+			if err := v.RegisterTranslation(c.tag, trans, v.customRegistrationFn(c.tag, text, c.override), translateFn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// translatorsFor returns the translators serving lang: the one of the universal translator and
+// the default translator when it uses the same locale.
+func (v *Validator) translatorsFor(lang string) []ut.Translator {
+	var targets []ut.Translator
+	if trans, ok := v.translators[lang]; ok {
+		targets = append(targets, trans)
+	}
+	if v.trans != nil && v.trans.Locale() == lang && (len(targets) == 0 || targets[0] != v.trans) {
+		targets = append(targets, v.trans)
+	}
+
+	return targets
+}
+
+// allTranslators returns every translator of the Validator.
+func (v *Validator) allTranslators() []ut.Translator {
+	targets := make([]ut.Translator, 0, len(v.translators)+1)
+	for _, trans := range v.translators {
+		targets = append(targets, trans)
+	}
+	if v.trans != nil {
+		targets = append(targets, v.trans)
+	}
+
+	return targets
+}
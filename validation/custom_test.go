@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+type customRequest struct {
+	Mobile string `json:"mobile" validate:"mobile"`
+}
+
+func isMobile(fl validator.FieldLevel) bool {
+	return strings.HasPrefix(fl.Field().String(), "1") && len(fl.Field().String()) == 11
+}
+
+func TestAddCustomTranslatesPerLocale(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	_, err = v.AddCustom(NewCustomValidator().
+		Tag("mobile").
+		Func(isMobile).
+		Translation("en", "{0} must be a valid mobile number").
+		Translation("zh", "{0}必须是有效的手机号码"))
+	require.NoError(t, err)
+
+	req := &customRequest{Mobile: "123"}
+	require.Equal(t, "mobile must be a valid mobile number",
+		validationMessage(t, WithLocale(context.Background(), "en"), v, req))
+	require.Equal(t, "mobile必须是有效的手机号码",
+		validationMessage(t, WithLocale(context.Background(), "zh"), v, req))
+	require.NoError(t, v.ValidateStruct(context.Background(), &customRequest{Mobile: "13800000000"}))
+}
+
+func TestAddCustomFuncCtx(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	_, err = v.AddCustom(NewCustomValidator().
+		Tag("mobile").
+		Func(func(validator.FieldLevel) bool { return true }).
+		FuncCtx(func(ctx context.Context, fl validator.FieldLevel) bool {
+			return ctx != nil && fl.Field().String() == "42"
+		}).
+		Translation("en", "{0} is not allowed"))
+	require.NoError(t, err)
+
+	ctx := WithLocale(context.Background(), "en")
+	require.NoError(t, v.ValidateStruct(ctx, &customRequest{Mobile: "42"}))
+	require.Equal(t, "mobile is not allowed", validationMessage(t, ctx, v, &customRequest{Mobile: "43"}))
+}
+
+func TestAddCustomReportsRegistrationFailures(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	_, err = v.AddCustom(
+		NewCustomValidator().Tag("").Func(isMobile),
+		NewCustomValidator().Tag("nofunc"),
+		NewCustomValidator().Tag("unknownlang").Func(isMobile).Translation("xx", "{0}"),
+		NewCustomValidator().Tag("mobile").Func(isMobile),
+	)
+	require.Error(t, err)
+	require.ErrorContains(t, err, `tag ""`)
+	require.ErrorContains(t, err, `tag "nofunc"`)
+	require.ErrorContains(t, err, `tag "unknownlang"`)
+	require.NotContains(t, err.Error(), `tag "mobile"`)
+	require.Len(t, v.customValidator, 1)
+}
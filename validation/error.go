@@ -3,17 +3,24 @@ package validation
 import (
 	"bytes"
 	"errors"
+	"net/http"
 	"strings"
 
 	xerr "github.com/starme/go-zero/httpx/errors"
 )
 
-// ValidateError aggregates validation failures for later reporting.
+// ValidateError aggregates validation failures for later reporting. Failures raised by the
+// validator are stored as *FieldViolation values.
 type ValidateError []error
 
-// Code returns a consistent HTTP code for validation failures.
+// Code returns a consistent business code for validation failures.
 func (e ValidateError) Code() xerr.HttpCode {
-	return 100
+	return xerr.CodeValidation
+}
+
+// Status reports validation failures as 400 Bad Request.
+func (e ValidateError) Status() int {
+	return http.StatusBadRequest
 }
 
 // Error builds a newline-separated message containing all validation error strings.
@@ -33,3 +40,29 @@ func (e ValidateError) Error() string {
 func (e ValidateError) AddString(msg string) ValidateError {
 	return append(e, errors.New(msg))
 }
+
+// AddViolation appends a field-level validation failure.
+func (e ValidateError) AddViolation(v FieldViolation) ValidateError {
+	return append(e, &v)
+}
+
+// Violations lists every failure as a FieldViolation. Plain messages added with AddString
+// only carry their Message.
+func (e ValidateError) Violations() []FieldViolation {
+	violations := make([]FieldViolation, 0, len(e))
+	for _, err := range e {
+		var violation *FieldViolation
+		if errors.As(err, &violation) {
+			violations = append(violations, *violation)
+		} else {
+			violations = append(violations, FieldViolation{Message: err.Error()})
+		}
+	}
+
+	return violations
+}
+
+// Data exposes the violations so error responses can return them to clients.
+func (e ValidateError) Data() any {
+	return e.Violations()
+}
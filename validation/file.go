@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-playground/validator/v10"
+)
+
+// fileSizeUnits maps the size suffixes accepted by the maxsize tag to their multipliers.
+var fileSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// registerFileValidations adds the tags validating uploaded *multipart.FileHeader fields:
+//
+//	maxsize=2MB                 file size, in bytes or with a KB, MB or GB suffix
+//	mimetype=image/png image/*  content type sniffed from the file
+//	ext=.jpg .png               file name extension, case-insensitive
+//
+// Slices of file headers are valid when every file is.
+func registerFileValidations(v *validator.Validate) error {
+	rules := map[string]validator.Func{
+		"maxsize":  validateMaxSize,
+		"mimetype": validateMimeType,
+		"ext":      validateExt,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("register validation for tag %q failed: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+func validateMaxSize(fl validator.FieldLevel) bool {
+	limit, err := ParseFileSize(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("validation: maxsize: %v", err))
+	}
+
+	return allFiles(fl.Field(), func(fh *multipart.FileHeader) bool {
+		return fh.Size <= limit
+	})
+}
+
+func validateMimeType(fl validator.FieldLevel) bool {
+	allowed := strings.Fields(fl.Param())
+
+	return allFiles(fl.Field(), func(fh *multipart.FileHeader) bool {
+		ok, err := MatchFileType(fh, allowed...)
+		return err == nil && ok
+	})
+}
+
+func validateExt(fl validator.FieldLevel) bool {
+	allowed := strings.Fields(fl.Param())
+
+	return allFiles(fl.Field(), func(fh *multipart.FileHeader) bool {
+		ext := strings.ToLower(filepath.Ext(fh.Filename))
+		for _, candidate := range allowed {
+			if ext != "" && strings.TrimPrefix(ext, ".") == strings.TrimPrefix(strings.ToLower(candidate), ".") {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// allFiles reports whether check accepts every file held by field, which is a file header
+// (as dereferenced by the validator) or a slice of *multipart.FileHeader.
+func allFiles(field reflect.Value, check func(*multipart.FileHeader) bool) bool {
+	switch fh := field.Interface().(type) {
+	case multipart.FileHeader:
+		return check(&fh)
+	case *multipart.FileHeader:
+		return fh == nil || check(fh)
+	case []*multipart.FileHeader:
+		for _, f := range fh {
+			if f != nil && !check(f) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		panic(fmt.Sprintf("validation: file tags cannot be used on %s", field.Type()))
+	}
+}
+
+// ParseFileSize parses sizes such as "512", "200KB" or "2MB". Units are powers of 1024 and
+// case-insensitive.
+func ParseFileSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range fileSizeUnits {
+		if trimmed, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(trimmed), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid file size %q", s)
+	}
+
+	return n * multiplier, nil
+}
+
+// MatchFileType sniffs the content of fh and reports whether it matches one of allowed. Entries
+// may be wildcards such as "image/*", and a type also matches the types it specializes, e.g.
+// "text/plain" accepts JSON.
+func MatchFileType(fh *multipart.FileHeader, allowed ...string) (bool, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	detected, err := mimetype.DetectReader(file)
+	if err != nil {
+		return false, err
+	}
+
+	return matchMimeType(detected, allowed), nil
+}
+
+// MatchContent sniffs the type of data, such as the first bytes of a streamed upload, and
+// reports whether it matches one of allowed like MatchFileType.
+func MatchContent(data []byte, allowed ...string) bool {
+	return matchMimeType(mimetype.Detect(data), allowed)
+}
+
+func matchMimeType(detected *mimetype.MIME, allowed []string) bool {
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if prefix, ok := strings.CutSuffix(candidate, "/*"); ok {
+			mediaType, _, _ := mime.ParseMediaType(detected.String())
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		for m := detected; m != nil; m = m.Parent() {
+			if m.Is(candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
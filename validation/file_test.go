@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fileRequest struct {
+	Avatar *multipart.FileHeader   `file:"avatar" validate:"omitempty,maxsize=1KB,mimetype=image/*,ext=.PNG .jpg"`
+	Docs   []*multipart.FileHeader `file:"docs" validate:"maxsize=4,mimetype=text/plain"`
+}
+
+func TestParseFileSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "2KB": 2048, "1 mb": 1 << 20, "3GB": 3 << 30, "7b": 7} {
+		got, err := ParseFileSize(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+
+	_, err := ParseFileSize("lots")
+	require.Error(t, err)
+}
+
+func TestFileTags(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	valid := &fileRequest{
+		Avatar: newFileHeader(t, "avatar", "me.png", png),
+		Docs:   []*multipart.FileHeader{newFileHeader(t, "docs", "a.txt", []byte("abc"))},
+	}
+	require.NoError(t, v.ValidateStruct(context.Background(), valid))
+
+	en := WithLocale(context.Background(), "en")
+	zh := WithLocale(context.Background(), "zh")
+
+	wrongExt := &fileRequest{Avatar: newFileHeader(t, "avatar", "me.gif", png)}
+	require.Equal(t, "avatar must have one of the extensions .PNG .jpg", validationMessage(t, en, v, wrongExt))
+
+	notImage := &fileRequest{Avatar: newFileHeader(t, "avatar", "me.png", []byte("hello"))}
+	require.Equal(t, "avatar必须是image/*类型的文件", validationMessage(t, zh, v, notImage))
+
+	tooLarge := &fileRequest{Docs: []*multipart.FileHeader{
+		newFileHeader(t, "docs", "a.txt", []byte("abc")),
+		newFileHeader(t, "docs", "b.txt", []byte("abcdef")),
+	}}
+	require.Equal(t, "docs must not be larger than 4", validationMessage(t, en, v, tooLarge))
+	require.Equal(t, "docs不能大于4", validationMessage(t, zh, v, tooLarge))
+}
+
+// newFileHeader builds a file header the way the standard library does when parsing a form.
+func newFileHeader(t *testing.T, field, name string, content []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	w, err := mw.CreateFormFile(field, name)
+	require.NoError(t, err)
+	_, err = w.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	form, err := multipart.NewReader(&body, mw.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() })
+
+	return form.File[field][0]
+}
@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type localeKey struct{}
+
+// WithLocale returns a context that makes Validate translate messages in the first of langs
+// that has a registered translator.
+func WithLocale(ctx context.Context, langs ...string) context.Context {
+	return context.WithValue(ctx, localeKey{}, langs)
+}
+
+// LocaleFromContext returns the preferred languages stored by WithLocale or LocaleMiddleware.
+func LocaleFromContext(ctx context.Context) []string {
+	langs, _ := ctx.Value(localeKey{}).([]string)
+	return langs
+}
+
+// LocaleMiddleware stores the languages accepted by the client, parsed from Accept-Language,
+// in the request context so validation messages are translated per request.
+func LocaleMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if langs := ParseAcceptLanguage(r.Header.Get("Accept-Language")); len(langs) > 0 {
+			r = r.WithContext(WithLocale(r.Context(), langs...))
+		}
+
+		next(w, r)
+	}
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header ordered by quality.
+// Regional tags are followed by their base language, so "zh-CN,en;q=0.8" yields
+// ["zh-CN", "zh", "en"]. Wildcards and languages with q=0 are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(key, "q") {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	seen := make(map[string]struct{}, len(tags)*2)
+	langs := make([]string, 0, len(tags)*2)
+	add := func(lang string) {
+		key := strings.ToLower(lang)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		langs = append(langs, lang)
+	}
+
+	for _, t := range tags {
+		add(t.tag)
+		if base, _, ok := strings.Cut(t.tag, "-"); ok {
+			add(base)
+		}
+	}
+
+	return langs
+}
+
+// localeCandidates lists the translator names to try for a language tag, e.g. "zh-CN" is
+// tried as "zh-CN" and "zh_CN" to match the locale names of go-playground/locales, then as
+// its base tag "zh".
+func localeCandidates(lang string) []string {
+	candidates := []string{lang}
+	if normalized := strings.ReplaceAll(lang, "-", "_"); normalized != lang {
+		candidates = append(candidates, normalized)
+	}
+	if base, _, found := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); found && base != "" {
+		candidates = append(candidates, base)
+	}
+
+	return candidates
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header   string
+		expected []string
+	}{
+		{header: "", expected: []string{}},
+		{header: "zh-CN", expected: []string{"zh-CN", "zh"}},
+		{header: "en;q=0.5, zh-CN,zh;q=0.9", expected: []string{"zh-CN", "zh", "en"}},
+		{header: "fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", expected: []string{"fr-CH", "fr", "en"}},
+		{header: "de;q=0, en", expected: []string{"en"}},
+		{header: "en;q=abc, zh", expected: []string{"zh"}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.header, func(t *testing.T) {
+			require.Equal(t, tt.expected, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestLocaleMiddlewareStoresLanguages(t *testing.T) {
+	var langs []string
+	handler := LocaleMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		langs = LocaleFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "zh-CN,en;q=0.8")
+	handler(httptest.NewRecorder(), req)
+
+	require.Equal(t, []string{"zh-CN", "zh", "en"}, langs)
+}
+
+type localizedRequest struct {
+	Account string `json:"account" validate:"required"`
+}
+
+func TestValidateTranslatesPerRequestLocale(t *testing.T) {
+	cases := []struct {
+		name     string
+		langs    []string
+		expected string
+	}{
+		{name: "chinese with region fallback", langs: []string{"zh-CN", "zh"}, expected: "account为必填字段"},
+		{name: "english", langs: []string{"en-US", "en"}, expected: "account is a required field"},
+		{name: "unsupported then english", langs: []string{"fr", "en"}, expected: "account is a required field"},
+		{name: "regional tag only", langs: []string{"en-US"}, expected: "account is a required field"},
+		{name: "regional chinese only", langs: []string{"zh_TW"}, expected: "account为必填字段"},
+		{name: "unsupported falls back to english", langs: []string{"fr"}, expected: "account is a required field"},
+		{name: "no locale falls back to english", expected: "account is a required field"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(WithLocale(context.Background(), tt.langs...), &localizedRequest{})
+
+			var ve ValidateError
+			require.True(t, errors.As(err, &ve))
+			require.Equal(t, tt.expected, ve.Error())
+		})
+	}
+}
@@ -3,23 +3,74 @@ package validation
 import (
 	"fmt"
 
+	"github.com/go-playground/locales"
+	localeen "github.com/go-playground/locales/en"
+	localezh "github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/go-playground/validator/v10/translations/en"
 	"github.com/go-playground/validator/v10/translations/zh"
 )
 
+// FallbackLocale is the language used when no requested language has a translator.
+const FallbackLocale = "en"
+
 // TranslationRegistrationFunc registers translations for a validator and translator pair.
 type TranslationRegistrationFunc func(*validator.Validate, ut.Translator) error
 
 // DefaultTranslationRegistry maps locale codes to their registration helpers.
 var DefaultTranslationRegistry = map[string]TranslationRegistrationFunc{
 	"zh": func(v *validator.Validate, trans ut.Translator) error {
-		return zh.RegisterDefaultTranslations(v, trans)
+		if err := zh.RegisterDefaultTranslations(v, trans); err != nil {
+			return err
+		}
+
+		return registerBuiltinTranslations(v, trans, "zh")
 	},
 	"en": func(v *validator.Validate, trans ut.Translator) error {
-		return en.RegisterDefaultTranslations(v, trans)
+		if err := en.RegisterDefaultTranslations(v, trans); err != nil {
+			return err
+		}
+
+		return registerBuiltinTranslations(v, trans, "en")
+	},
+}
+
+// builtinTranslations holds the messages of the rules added by this package, per language of
+// DefaultTranslationRegistry: the file tags and the rules reported by strict JSON decoding.
+var builtinTranslations = map[string]map[string]string{
+	"en": {
+		"maxsize":       "{0} must not be larger than {1}",
+		"mimetype":      "{0} must be a file of type {1}",
+		"ext":           "{0} must have one of the extensions {1}",
+		"unknown_field": "{0} is not a known field",
+		"duplicate_key": "{0} must not be specified more than once",
+		"trailing_data": "the request body must contain a single JSON value",
 	},
+	"zh": {
+		"maxsize":       "{0}不能大于{1}",
+		"mimetype":      "{0}必须是{1}类型的文件",
+		"ext":           "{0}的扩展名必须是{1}之一",
+		"unknown_field": "{0}是未知字段",
+		"duplicate_key": "{0}不能重复出现",
+		"trailing_data": "请求体只能包含一个JSON值",
+	},
+}
+
+// DefaultLocaleRegistry maps locale codes to the locale data loaded into universal translators.
+var DefaultLocaleRegistry = map[string]locales.Translator{
+	"zh": localezh.New(),
+	"en": localeen.New(),
+}
+
+// RegisterLocale adds locale data for lang so universal translators can serve it. Pair it with
+// RegisterLocaleTranslation to provide the validation messages of that language.
+func RegisterLocale(lang string, locale locales.Translator) {
+	if lang == "" || locale == nil {
+		return
+	}
+
+	DefaultLocaleRegistry[lang] = locale
 }
 
 // RegisterLocaleTranslation adds a custom translation registration function for a locale.
@@ -39,3 +90,66 @@ func RegisterTranslationsForLang(v *validator.Validate, trans ut.Translator, lan
 
 	return fmt.Errorf("no translation registered for language %q", lang)
 }
+
+// registerTranslatorTranslations registers the validation messages of the locale of trans on
+// it. Locales missing from DefaultLocaleRegistry or DefaultTranslationRegistry are skipped.
+func registerTranslatorTranslations(v *validator.Validate, trans ut.Translator) error {
+	for lang, locale := range DefaultLocaleRegistry {
+		if locale.Locale() != trans.Locale() {
+			continue
+		}
+		if _, ok := DefaultTranslationRegistry[lang]; !ok {
+			continue
+		}
+
+		if err := RegisterTranslationsForLang(v, trans, lang); err != nil {
+			return fmt.Errorf("register translations for %q: %w", lang, err)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// registerBuiltinTranslations registers the messages of the rules added by this package for
+// lang on trans.
+func registerBuiltinTranslations(v *validator.Validate, trans ut.Translator, lang string) error {
+	for tag, text := range builtinTranslations[lang] {
+		err := v.RegisterTranslation(tag, trans, defaultRegistrationFunc(tag, text, false),
+			validator.TranslationFunc(defaultTranslateFunc))
+		if err != nil {
+			return fmt.Errorf("register translation for tag %q failed: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// NewUniversalTranslator builds a universal translator holding every locale in
+// DefaultLocaleRegistry and registers the validation messages of each locale that has an
+// entry in DefaultTranslationRegistry on v.
+func NewUniversalTranslator(v *validator.Validate) (*ut.UniversalTranslator, error) {
+	fallback, ok := DefaultLocaleRegistry[FallbackLocale]
+	if !ok {
+		return nil, fmt.Errorf("no locale registered for fallback language %q", FallbackLocale)
+	}
+
+	supported := make([]locales.Translator, 0, len(DefaultLocaleRegistry))
+	for _, locale := range DefaultLocaleRegistry {
+		supported = append(supported, locale)
+	}
+	uni := ut.New(fallback, supported...)
+
+	for lang, locale := range DefaultLocaleRegistry {
+		if _, ok = DefaultTranslationRegistry[lang]; !ok {
+			continue
+		}
+
+		trans, _ := uni.GetTranslator(locale.Locale())
+		if err := RegisterTranslationsForLang(v, trans, lang); err != nil {
+			return nil, fmt.Errorf("register translations for %q: %w", lang, err)
+		}
+	}
+
+	return uni, nil
+}
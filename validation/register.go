@@ -1,7 +1,7 @@
 package validation
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -10,6 +10,7 @@ import (
 	"github.com/go-playground/locales"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	"github.com/zeromicro/go-zero/core/logx"
 )
 
 // TransFn defines a translation helper compatible with validator.TranslationFunc.
@@ -18,81 +19,179 @@ type TransFn validator.TranslationFunc
 // RegisFn builds a validator translation registration function.
 type RegisFn func(tag string, translation string, override bool) validator.RegisterTranslationsFunc
 
-// CustomValidator holds metadata for a custom validator and its translations.
-type CustomValidator struct {
-	tag                  string
-	validateFunc         validator.Func
-	translation          string
-	override             bool
-	customRegistrationFn validator.RegisterTranslationsFunc
-	customTranslateFn    validator.TranslationFunc
-}
-
-// NewCustomValidator creates an empty CustomValidator ready for chained configuration.
-func NewCustomValidator() *CustomValidator {
-	return &CustomValidator{}
-}
-
 // Validator extends the go-playground validator and stores translation helpers.
 type Validator struct {
 	*validator.Validate
 
 	trans                ut.Translator
+	uni                  *ut.UniversalTranslator
+	translators          map[string]ut.Translator
 	customValidator      []*CustomValidator
 	customTagNameFn      validator.TagNameFunc
 	customTranslateFn    TransFn
 	customRegistrationFn RegisFn
+	contextFns           []func(context.Context) context.Context
+	opts                 []Option
+}
+
+// Option configures a Validator built by New.
+type Option func(*Validator)
+
+// WithTranslator sets the translator used when the context names no supported language. New
+// registers the validation messages of its locale on it when that locale is registered in
+// DefaultLocaleRegistry and DefaultTranslationRegistry.
+func WithTranslator(trans ut.Translator) Option {
+	return func(v *Validator) {
+		v.trans = trans
+	}
+}
+
+// WithTagNameFunc sets how field names are reported in errors. The default uses the json tag.
+func WithTagNameFunc(fn validator.TagNameFunc) Option {
+	return func(v *Validator) {
+		v.customTagNameFn = fn
+	}
+}
+
+// WithTranslateFunc sets the translation function used for custom validators.
+func WithTranslateFunc(fn TransFn) Option {
+	return func(v *Validator) {
+		v.customTranslateFn = fn
+	}
+}
+
+// WithRegistrationFunc sets how translations of custom validators are registered.
+func WithRegistrationFunc(fn RegisFn) Option {
+	return func(v *Validator) {
+		v.customRegistrationFn = fn
+	}
+}
+
+// New builds an independent Validator. Every locale in DefaultLocaleRegistry is loaded into its
+// universal translator, so messages follow the languages stored in the context by WithLocale
+// or LocaleMiddleware.
+func New(opts ...Option) (*Validator, error) {
+	v := &Validator{
+		Validate:             validator.New(),
+		customValidator:      []*CustomValidator{},
+		customTagNameFn:      defaultTagNameFunc,
+		customTranslateFn:    defaultTranslateFunc,
+		customRegistrationFn: defaultRegistrationFunc,
+		opts:                 opts,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if err := registerFileValidations(v.Validate); err != nil {
+		return nil, err
+	}
+	if v.customTagNameFn != nil {
+		v.RegisterTagNameFunc(v.customTagNameFn)
+	}
+
+	uni, err := NewUniversalTranslator(v.Validate)
+	if err != nil {
+		return nil, err
+	}
+	v.uni = uni
+
+	if v.trans != nil {
+		if err = registerTranslatorTranslations(v.Validate, v.trans); err != nil {
+			return nil, err
+		}
+	}
+
+	v.translators = make(map[string]ut.Translator, len(DefaultLocaleRegistry))
+	for lang, locale := range DefaultLocaleRegistry {
+		if trans, found := uni.GetTranslator(locale.Locale()); found {
+			v.translators[lang] = trans
+		}
+	}
+
+	return v, nil
 }
 
 var (
-	validatorOnce    sync.Once
-	defaultValidator *Validator
+	defaultValidator   *Validator
+	defaultValidatorMu sync.Mutex
 )
 
-// NewValidator returns a shared Validator configured with defaults and optional translator.
-func NewValidator(trans ut.Translator) *Validator {
-	validatorOnce.Do(func() {
-		defaultValidator = &Validator{
-			Validate:             validator.New(),
-			trans:                trans,
-			customValidator:      []*CustomValidator{},
-			customTagNameFn:      defaultTagNameFunc,
-			customTranslateFn:    defaultTranslateFunc,
-			customRegistrationFn: defaultRegistrationFunc,
+// Default returns the Validator used by Validate, building one with New on first use.
+func Default() *Validator {
+	defaultValidatorMu.Lock()
+	defer defaultValidatorMu.Unlock()
+
+	if defaultValidator == nil {
+		v, err := New()
+		if err != nil {
+			panic(fmt.Sprintf("validation: build default validator: %v", err))
 		}
-	})
+		defaultValidator = v
+	}
 
 	return defaultValidator
 }
 
-// AddCustom registers custom validation and translation rules on the validator.
-func (v *Validator) AddCustom(customValidator ...*CustomValidator) (*Validator, error) {
-	var errs []error
-	for _, t := range customValidator {
-		if err := v.RegisterValidation(t.tag, t.validateFunc); err != nil {
-			continue
-		}
+// SetDefault replaces the Validator used by Validate.
+func SetDefault(v *Validator) {
+	if v == nil {
+		return
+	}
 
-		if t.customRegistrationFn == nil {
-			t.customRegistrationFn = v.customRegistrationFn(t.tag, t.translation, t.override)
-		}
+	defaultValidatorMu.Lock()
+	defaultValidator = v
+	defaultValidatorMu.Unlock()
+}
 
-		if t.customTranslateFn == nil {
-			t.customTranslateFn = validator.TranslationFunc(v.customTranslateFn)
-		}
+// NewValidator builds a new Validator with the options and custom validators of the default
+// Validator used by Validate, and with trans as its fallback translator when trans is not nil.
+// The default is left untouched. It panics if the locale registries are misconfigured.
+//
+// Deprecated: use New with WithTranslator, and SetDefault when the package-level
+// Validate should use it.
+func NewValidator(trans ut.Translator) *Validator {
+	d := Default()
 
-		if err := v.RegisterTranslation(t.tag, v.trans, t.customRegistrationFn, t.customTranslateFn); err != nil {
-			errs = append(errs, fmt.Errorf("register translation for tag %q failed: %w", t.tag, err))
-		}
+	opts := append([]Option(nil), d.opts...)
+	if trans != nil {
+		opts = append(opts, WithTranslator(trans))
 	}
+	v, err := New(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("validation: build validator: %v", err))
+	}
+	if _, err = v.AddCustom(d.customValidator...); err != nil {
+		panic(fmt.Sprintf("validation: build validator: %v", err))
+	}
+
+	return v
+}
 
-	if len(errs) > 0 {
-		return v, errors.Join(errs...)
+// translator returns the translator for the first language preferred in ctx that is available,
+// trying the base tag of a regional language (e.g. "en" for "en-US") before the next one. It
+// falls back to the translator the Validator was created with, then to FallbackLocale.
+func (v *Validator) translator(ctx context.Context) ut.Translator {
+	if v.uni == nil {
+		return v.trans
 	}
 
-	v.customValidator = append(v.customValidator, customValidator...)
+	for _, lang := range LocaleFromContext(ctx) {
+		for _, candidate := range localeCandidates(lang) {
+			if trans, found := v.uni.GetTranslator(candidate); found {
+				return trans
+			}
+		}
+	}
+	if v.trans != nil {
+		return v.trans
+	}
 
-	return v, nil
+	if trans, found := v.uni.GetTranslator(FallbackLocale); found {
+		return trans
+	}
+
+	return nil
 }
 
 // defaultRegistrationFunc registers the tag translation when no override is needed.
@@ -102,35 +201,50 @@ func defaultRegistrationFunc(tag string, translation string, override bool) vali
 	}
 }
 
-// defaultTranslateFunc returns the translated validation message or falls back to the error string.
+// defaultTranslateFunc returns the translated validation message or logs the missing translation
+// and falls back to the error string.
 func defaultTranslateFunc(ut ut.Translator, fe validator.FieldError) string {
 	t, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
 	if err != nil {
-		fmt.Printf("警告: 翻译字段错误: %#v\n", fe)
+		logx.Errorf("validation: no %s translation for tag %q: %v", ut.Locale(), fe.Tag(), err)
 		return fe.(error).Error()
 	}
 
 	return t
 }
 
-// defaultTagNameFunc uses the json tag as the field name for validation errors.
+// bindingTags lists the struct tags naming a field in a request, in order of preference.
+var bindingTags = []string{"json", "form", "path", "header", "cookie", "file"}
+
+// defaultTagNameFunc uses the json tag as the field name for validation errors, falling back
+// to the form, path, header, cookie and file tags used by the request package.
 func defaultTagNameFunc(fld reflect.StructField) string {
-	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	for _, key := range bindingTags {
+		tag, ok := fld.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
 
-	if name == "-" {
-		return ""
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
 	}
 
-	return name
+	return ""
 }
 
-// NewTranslator builds a universal translator with the requested default language.
+// NewTranslator builds a universal translator with the requested default language. An
+// unsupported language is logged and the first of supportLocales is used instead.
 func NewTranslator(defaultLang string, supportLocales ...locales.Translator) ut.Translator {
 	translator := ut.New(supportLocales[0], supportLocales[1:]...)
 
 	trans, found := translator.GetTranslator(defaultLang)
 	if !found {
-		fmt.Println("translator not found")
+		logx.Errorf("validation: no translator for %q, using %s", defaultLang, trans.Locale())
 	}
 
 	return trans
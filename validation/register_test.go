@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/locales/zh"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+type registerRequest struct {
+	Account string `json:"account" form:"user" validate:"required"`
+}
+
+func TestNewBuildsIndependentValidators(t *testing.T) {
+	first, err := New()
+	require.NoError(t, err)
+	second, err := New(WithTagNameFunc(func(fld reflect.StructField) string {
+		return fld.Tag.Get("form")
+	}))
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	ctx := WithLocale(context.Background(), "en")
+	require.Equal(t, "account is a required field", validationMessage(t, ctx, first, &registerRequest{}))
+	require.Equal(t, "user is a required field", validationMessage(t, ctx, second, &registerRequest{}))
+}
+
+func TestWithTranslatorSetsDefaultLanguage(t *testing.T) {
+	v, err := New(WithTranslator(NewTranslator("zh", zh.New(), zh.New())))
+	require.NoError(t, err)
+
+	require.Equal(t, "account为必填字段", validationMessage(t, context.Background(), v, &registerRequest{}))
+	require.Equal(t, "account is a required field",
+		validationMessage(t, WithLocale(context.Background(), "en"), v, &registerRequest{}))
+}
+
+func TestSetDefaultReplacesPackageValidator(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+
+	custom, err := New(WithTagNameFunc(func(fld reflect.StructField) string {
+		return strings.ToUpper(fld.Name)
+	}))
+	require.NoError(t, err)
+	SetDefault(custom)
+	require.Same(t, custom, Default())
+
+	err = Validate(WithLocale(context.Background(), "en"), &registerRequest{})
+	require.EqualError(t, err, "ACCOUNT is a required field")
+}
+
+func TestNewValidatorKeepsDefault(t *testing.T) {
+	previous := Default()
+	t.Cleanup(func() { SetDefault(previous) })
+
+	custom, err := New(WithTagNameFunc(func(fld reflect.StructField) string {
+		return fld.Tag.Get("form")
+	}))
+	require.NoError(t, err)
+	_, err = custom.AddCustom(NewCustomValidator().Tag("even").Func(func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+	require.NoError(t, err)
+	SetDefault(custom)
+
+	trans := NewTranslator("zh", zh.New(), zh.New())
+	v := NewValidator(trans)
+	require.NotSame(t, custom, v)
+	require.Same(t, custom, Default())
+	require.Nil(t, custom.trans)
+	require.Same(t, trans, v.trans)
+	require.Equal(t, "user为必填字段", validationMessage(t, context.Background(), v, &registerRequest{}))
+
+	// The new validator keeps the options and custom validators of the default.
+	ctx := WithLocale(context.Background(), "en")
+	require.Equal(t, "user is a required field", validationMessage(t, ctx, v, &registerRequest{}))
+	require.Error(t, v.Var(3, "even"))
+}
+
+func validationMessage(t *testing.T, ctx context.Context, v *Validator, s any) string {
+	t.Helper()
+
+	err := v.ValidateStruct(ctx, s)
+
+	var ve ValidateError
+	require.True(t, errors.As(err, &ve))
+	return ve.Error()
+}
@@ -0,0 +1,32 @@
+package validation
+
+import "context"
+
+type serviceKey[T any] struct{}
+
+// Provide returns a context carrying svc, such as a repository or a clock, so that validation
+// rules registered with CustomValidator.FuncCtx can retrieve it with Service. Each type holds
+// one service; providing the same type again replaces it.
+func Provide[T any](ctx context.Context, svc T) context.Context {
+	return context.WithValue(ctx, serviceKey[T]{}, svc)
+}
+
+// Service returns the service of type T stored in ctx by Provide or WithService.
+func Service[T any](ctx context.Context) (T, bool) {
+	svc, ok := ctx.Value(serviceKey[T]{}).(T)
+	return svc, ok
+}
+
+// WithService makes svc available to every validation run by the Validator. A service of the
+// same type provided on the context passed to ValidateStruct takes precedence.
+func WithService[T any](svc T) Option {
+	return func(v *Validator) {
+		v.contextFns = append(v.contextFns, func(ctx context.Context) context.Context {
+			if _, ok := Service[T](ctx); ok {
+				return ctx
+			}
+
+			return Provide(ctx, svc)
+		})
+	}
+}
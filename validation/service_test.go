@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+type userRepo interface {
+	Exists(ctx context.Context, name string) bool
+}
+
+type fakeUserRepo map[string]bool
+
+func (r fakeUserRepo) Exists(_ context.Context, name string) bool {
+	return r[name]
+}
+
+type signupRequest struct {
+	Username string `json:"username" validate:"unique_username"`
+}
+
+func newUniqueValidator(t *testing.T, opts ...Option) *Validator {
+	t.Helper()
+
+	v, err := New(opts...)
+	require.NoError(t, err)
+	_, err = v.AddCustom(NewCustomValidator().
+		Tag("unique_username").
+		FuncCtx(func(ctx context.Context, fl validator.FieldLevel) bool {
+			repo, ok := Service[userRepo](ctx)
+			return ok && !repo.Exists(ctx, fl.Field().String())
+		}).
+		Translation("en", "{0} is already taken"))
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestFuncCtxReadsProvidedService(t *testing.T) {
+	v := newUniqueValidator(t)
+	ctx := WithLocale(Provide[userRepo](context.Background(), fakeUserRepo{"alice": true}), "en")
+
+	require.NoError(t, v.ValidateStruct(ctx, &signupRequest{Username: "bob"}))
+	require.Equal(t, "username is already taken", validationMessage(t, ctx, v, &signupRequest{Username: "alice"}))
+}
+
+func TestWithServiceProvidesDefaultService(t *testing.T) {
+	v := newUniqueValidator(t, WithService[userRepo](fakeUserRepo{"alice": true}))
+	ctx := WithLocale(context.Background(), "en")
+
+	require.Error(t, v.ValidateStruct(ctx, &signupRequest{Username: "alice"}))
+
+	// A service provided for the request takes precedence over the validator default.
+	ctx = Provide[userRepo](ctx, fakeUserRepo{})
+	require.NoError(t, v.ValidateStruct(ctx, &signupRequest{Username: "alice"}))
+}
+
+func TestValidateStructReturnsContextError(t *testing.T) {
+	v := newUniqueValidator(t, WithService[userRepo](fakeUserRepo{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, v.ValidateStruct(ctx, &signupRequest{Username: "bob"}), context.Canceled)
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validatable is implemented by request structs with invariants that span several fields,
+// such as "start must be before end". Validate runs after the tag rules, even when some of
+// them failed, so every problem is reported at once.
+//
+// Validate reports failures by returning a ValidateError or *FieldViolation values, joined
+// with errors.Join when there are several. Field is the Go field path, e.g. "EndAt"; Name
+// and Message are derived from it and from Rule and Param when left empty, so translations
+// registered for the rule apply. Any other error aborts validation and is returned unchanged.
+type Validatable interface {
+	Validate(ctx context.Context) error
+}
+
+// AddStructValidation registers struct-level rules for the given types. Failures reported with
+// validator.StructLevel.ReportError are translated through the translations of their tag, see
+// AddTranslation, and carry the field paths of the reported fields.
+func (v *Validator) AddStructValidation(fn validator.StructLevelFuncCtx, types ...any) *Validator {
+	v.RegisterStructValidationCtx(fn, types...)
+	return v
+}
+
+// AddTranslation registers the message template of tag for lang, a key of DefaultLocaleRegistry
+// such as "zh". It is meant for tags reported by struct-level rules and Validatable values that
+// have no validation function of their own. Templates use {0} for the field name and {1} for
+// the tag parameter.
+func (v *Validator) AddTranslation(lang, tag, text string) error {
+	targets := v.translatorsFor(lang)
+	if len(targets) == 0 {
+		return fmt.Errorf("no translator available for language %q", lang)
+	}
+
+	for _, trans := range targets {
+		err := v.RegisterTranslation(tag, trans, v.customRegistrationFn(tag, text, false),
+			validator.TranslationFunc(v.customTranslateFn))
+		if err != nil {
+			return fmt.Errorf("register translation for tag %q failed: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// Violation completes a violation reported outside of tag validation, such as by a decoder:
+// Name defaults to the JSON path of Field and Message to the translation of Rule in the
+// languages preferred in ctx.
+func (v *Validator) Violation(ctx context.Context, violation FieldViolation) *FieldViolation {
+	return v.completeViolation(violation, nil, v.translator(ctx))
+}
+
+// runValidatable calls the Validate hook of s and completes the violations it reports.
+func (v *Validator) runValidatable(ctx context.Context, s Validatable, root reflect.Type,
+	trans ut.Translator) (ValidateError, error) {
+	err := s.Validate(ctx)
+	if err == nil {
+		return nil, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	var reported ValidateError
+	if !errors.As(err, &reported) {
+		violations := joinedViolations(err)
+		if violations == nil {
+			return nil, err
+		}
+		reported = violations
+	}
+
+	result := make(ValidateError, 0, len(reported))
+	for _, e := range reported {
+		var violation *FieldViolation
+		if errors.As(e, &violation) {
+			result = append(result, v.completeViolation(*violation, root, trans))
+		} else {
+			result = append(result, e)
+		}
+	}
+
+	return result, nil
+}
+
+// joinedViolations returns the violations of a single *FieldViolation or of several joined
+// with errors.Join, or nil when err contains anything else.
+func joinedViolations(err error) ValidateError {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	violations := make(ValidateError, 0, len(errs))
+	for _, e := range errs {
+		var violation *FieldViolation
+		if !errors.As(e, &violation) {
+			return nil
+		}
+		violations = append(violations, violation)
+	}
+
+	return violations
+}
+
+// completeViolation fills in the JSON name and the translated message of a violation reported
+// by a Validatable value, naming fields like the tag name function of v.
+func (v *Validator) completeViolation(violation FieldViolation, root reflect.Type,
+	trans ut.Translator) *FieldViolation {
+	if violation.Name == "" {
+		violation.Name = jsonPath(root, violation.Field, v.customTagNameFn)
+	}
+	if violation.Message != "" {
+		return &violation
+	}
+
+	if trans != nil && violation.Rule != "" {
+		if message, err := trans.T(violation.Rule, violation.Name, violation.Param); err == nil {
+			violation.Message = message
+			return &violation
+		}
+	}
+	violation.Message = fmt.Sprintf("%s failed on the '%s' rule", violation.Name, violation.Rule)
+
+	return &violation
+}
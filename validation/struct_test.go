@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+type periodRequest struct {
+	Name    string `json:"name" validate:"required"`
+	StartAt int    `json:"start_at"`
+	EndAt   int    `json:"end_at"`
+}
+
+func (r *periodRequest) Validate(ctx context.Context) error {
+	if r.EndAt <= r.StartAt {
+		return &FieldViolation{Field: "EndAt", Rule: "gtfield", Param: "StartAt"}
+	}
+
+	return nil
+}
+
+type contactRequest struct {
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+}
+
+type failingRequest struct{}
+
+var errLookup = errors.New("lookup failed")
+
+func (failingRequest) Validate(context.Context) error {
+	return errLookup
+}
+
+func TestValidatableMergesViolations(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	err = v.ValidateStruct(WithLocale(context.Background(), "en"), &periodRequest{StartAt: 2, EndAt: 1})
+	var ve ValidateError
+	require.ErrorAs(t, err, &ve)
+
+	violations := ve.Violations()
+	require.Len(t, violations, 2)
+	require.Equal(t, "name", violations[0].Name)
+	require.Equal(t, FieldViolation{
+		Field:   "EndAt",
+		Name:    "end_at",
+		Rule:    "gtfield",
+		Param:   "StartAt",
+		Message: "end_at must be greater than StartAt",
+	}, violations[1])
+
+	require.NoError(t, v.ValidateStruct(context.Background(), &periodRequest{Name: "q1", StartAt: 1, EndAt: 2}))
+}
+
+func TestViolationNamesFollowTagNameFunc(t *testing.T) {
+	v, err := New(WithTagNameFunc(func(fld reflect.StructField) string {
+		return strings.ToUpper(fld.Name)
+	}))
+	require.NoError(t, err)
+
+	err = v.ValidateStruct(WithLocale(context.Background(), "en"), &periodRequest{StartAt: 2, EndAt: 1})
+	var ve ValidateError
+	require.ErrorAs(t, err, &ve)
+
+	violations := ve.Violations()
+	require.Len(t, violations, 2)
+	require.Equal(t, "NAME", violations[0].Name)
+	require.Equal(t, "ENDAT", violations[1].Name)
+	require.Equal(t, "ENDAT must be greater than StartAt", violations[1].Message)
+}
+
+func TestValidatableOtherErrorsAbort(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	require.ErrorIs(t, v.ValidateStruct(context.Background(), failingRequest{}), errLookup)
+}
+
+func TestAddStructValidationTranslatesReportedErrors(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+	require.NoError(t, v.AddTranslation("en", "phone_or_email", "{0} or email is required"))
+	require.NoError(t, v.AddTranslation("zh", "phone_or_email", "{0}和email至少填写一项"))
+
+	v.AddStructValidation(func(ctx context.Context, sl validator.StructLevel) {
+		req := sl.Current().Interface().(contactRequest)
+		if req.Phone == "" && req.Email == "" {
+			sl.ReportError(req.Phone, "phone", "Phone", "phone_or_email", "")
+		}
+	}, contactRequest{})
+
+	err = v.ValidateStruct(WithLocale(context.Background(), "en"), &contactRequest{})
+	var ve ValidateError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "Phone", ve.Violations()[0].Field)
+	require.Equal(t, "phone", ve.Violations()[0].Name)
+	require.Equal(t, "phone or email is required", ve.Error())
+
+	require.Equal(t, "phone和email至少填写一项",
+		validationMessage(t, WithLocale(context.Background(), "zh"), v, &contactRequest{}))
+	require.NoError(t, v.ValidateStruct(context.Background(), &contactRequest{Email: "a@b.c"}))
+}
@@ -3,29 +3,65 @@ package validation
 import (
 	"context"
 	"errors"
+	"reflect"
 
 	"github.com/go-playground/validator/v10"
 )
 
-// Validate runs struct validation using the shared validator instance and context.
+// Validate runs struct validation using the default Validator. Messages are translated
+// into the languages preferred in ctx when a matching translator is registered.
 func Validate(ctx context.Context, v any) error {
-	validatorInstance := NewValidator(nil)
+	return Default().ValidateStruct(ctx, v)
+}
+
+// ValidateStruct validates s and reports failures as a ValidateError of FieldViolation values,
+// translated into the languages preferred in ctx. ctx is passed to rules registered with
+// CustomValidator.FuncCtx; if it is canceled during validation, its error is returned instead.
+// When s implements Validatable, its Validate method runs after the tag rules and its
+// violations are merged into the same ValidateError.
+func (v *Validator) ValidateStruct(ctx context.Context, s any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, fn := range v.contextFns {
+		ctx = fn(ctx)
+	}
+
+	root := reflect.TypeOf(s)
+	trans := v.translator(ctx)
+	var ve ValidateError
+	if err := v.StructCtx(ctx, s); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 
-	if err := validatorInstance.Struct(v); err != nil {
 		var validationErrors validator.ValidationErrors
-		if errors.As(err, &validationErrors) {
-			var ve ValidateError
-			for _, fieldError := range validationErrors {
-				if validatorInstance.trans != nil {
-					ve = ve.AddString(fieldError.Translate(validatorInstance.trans))
-				} else {
-					ve = ve.AddString(fieldError.Error())
-				}
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+
+		for _, fieldError := range validationErrors {
+			message := fieldError.Error()
+			if trans != nil {
+				message = fieldError.Translate(trans)
 			}
-			return ve
+			ve = append(ve, newFieldViolation(root, fieldError, message, v.customTagNameFn))
 		}
+	}
 
-		return err
+	if validatable, ok := s.(Validatable); ok {
+		violations, err := v.runValidatable(ctx, validatable, root, trans)
+		if err != nil {
+			return err
+		}
+		ve = append(ve, violations...)
+	}
+
+	if len(ve) > 0 {
+		return ve
 	}
 
 	return nil
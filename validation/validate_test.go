@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type violationAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type violationItem struct {
+	SKU string `json:"sku" validate:"required"`
+}
+
+type violationRequest struct {
+	Account  string           `json:"account" validate:"required"`
+	Password string           `json:"password" validate:"min=8"`
+	Address  violationAddress `json:"address"`
+	Items    []violationItem  `json:"items" validate:"dive"`
+}
+
+func TestValidateReturnsFieldViolations(t *testing.T) {
+	req := violationRequest{
+		Password: "secret",
+		Items:    []violationItem{{SKU: "a"}, {}},
+	}
+
+	err := Validate(context.Background(), &req)
+
+	var ve ValidateError
+	require.True(t, errors.As(err, &ve))
+
+	violations := ve.Violations()
+	require.Len(t, violations, 4)
+
+	byField := make(map[string]FieldViolation, len(violations))
+	for _, violation := range violations {
+		byField[violation.Field] = violation
+	}
+
+	require.Equal(t, "account", byField["Account"].Name)
+	require.Equal(t, "required", byField["Account"].Rule)
+	require.NotEmpty(t, byField["Account"].Message)
+
+	require.Equal(t, "password", byField["Password"].Name)
+	require.Equal(t, "min", byField["Password"].Rule)
+	require.Equal(t, "8", byField["Password"].Param)
+	require.Equal(t, "secret", byField["Password"].Value)
+
+	require.Equal(t, "address.city", byField["Address.City"].Name)
+	require.Equal(t, "items[1].sku", byField["Items[1].SKU"].Name)
+}
+
+func TestValidateErrorViolationsKeepPlainMessages(t *testing.T) {
+	ve := ValidateError{}.
+		AddString("plain message").
+		AddViolation(FieldViolation{Field: "Name", Name: "name", Rule: "required", Message: "name is required"})
+
+	violations := ve.Violations()
+	require.Equal(t, []FieldViolation{
+		{Message: "plain message"},
+		{Field: "Name", Name: "name", Rule: "required", Message: "name is required"},
+	}, violations)
+	require.Equal(t, "plain message\nname is required", ve.Error())
+	require.Equal(t, violations, ve.Data())
+}
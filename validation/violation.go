@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldViolation describes a single field that failed validation.
+type FieldViolation struct {
+	// Field is the Go field path below the validated struct, e.g. "Address.City".
+	Field string `json:"field"`
+	// Name is the JSON path of the field as seen by clients, e.g. "address.city".
+	Name string `json:"name"`
+	// Rule is the validation tag that failed, e.g. "required".
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, e.g. "8" for "min=8".
+	Param string `json:"param,omitempty"`
+	// Message is the translated, human readable message.
+	Message string `json:"message"`
+	// Value is the offending value. It is never serialized to avoid echoing secrets.
+	Value any `json:"-"`
+}
+
+// Error returns the translated message of the violation.
+func (v *FieldViolation) Error() string {
+	return v.Message
+}
+
+// newFieldViolation converts a validator.FieldError raised while validating root; tagName
+// names the fields of Name like the tag name function registered on the validator.
+func newFieldViolation(root reflect.Type, fe validator.FieldError, message string,
+	tagName validator.TagNameFunc) *FieldViolation {
+	field := trimRoot(fe.StructNamespace())
+
+	return &FieldViolation{
+		Field:   field,
+		Name:    jsonPath(root, field, tagName),
+		Rule:    fe.Tag(),
+		Param:   fe.Param(),
+		Message: message,
+		Value:   fe.Value(),
+	}
+}
+
+// trimRoot drops the leading struct name from a validator namespace.
+func trimRoot(namespace string) string {
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+
+	return namespace
+}
+
+// jsonPath maps a Go field path such as "Items[0].Name" to the names tagName gives the fields,
+// e.g. "items[0].name". Fields that cannot be found, or that tagName leaves unnamed, keep
+// their Go name; a nil tagName keeps all Go names, as the validator does.
+func jsonPath(root reflect.Type, fieldPath string, tagName validator.TagNameFunc) string {
+	if fieldPath == "" {
+		return ""
+	}
+
+	var parts []string
+	current := root
+	for _, segment := range strings.Split(fieldPath, ".") {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for current != nil && current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+
+		jsonName := name
+		var next reflect.Type
+		if current != nil && current.Kind() == reflect.Struct {
+			if field, ok := current.FieldByName(name); ok {
+				next = field.Type
+				if field.Anonymous && field.Tag.Get("json") == "" {
+					current = next
+					continue
+				}
+				if tagName != nil {
+					if tagged := tagName(field); tagged != "" {
+						jsonName = tagged
+					}
+				}
+			}
+		}
+
+		parts = append(parts, jsonName+index)
+		current = elemForIndex(next, index)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// elemForIndex returns the element type addressed by a trailing index such as "[0]" or "[0][1]".
+func elemForIndex(t reflect.Type, index string) reflect.Type {
+	for i := strings.Count(index, "["); i > 0 && t != nil; i-- {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+
+	return t
+}